@@ -14,7 +14,11 @@ import (
 const version = "0.1.0"
 
 func main() {
-	apiURL := flag.String("api", "http://localhost:7070", "API server URL")
+	apiURL := flag.String("api", "http://localhost:7070", "API server URL (http://, https:// or unix:///path/to/gvid.sock)")
+	token := flag.String("token", os.Getenv("GVID_TOKEN"), "API bearer token (default: $GVID_TOKEN)")
+	caFile := flag.String("ca", "", "PEM CA bundle to verify an https daemon with")
+	certFile := flag.String("cert", "", "PEM client certificate for daemons that require mutual TLS")
+	keyFile := flag.String("key", "", "PEM private key for --cert")
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	flag.Parse()
 
@@ -23,7 +27,18 @@ func main() {
 		os.Exit(0)
 	}
 
-	m := tui.New(*apiURL)
+	client, err := tui.NewClient(*apiURL, tui.ClientOptions{
+		Token:    *token,
+		CAFile:   *caFile,
+		CertFile: *certFile,
+		KeyFile:  *keyFile,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	m := tui.New(client)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
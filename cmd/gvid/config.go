@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/api"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/config"
+)
+
+// flagSetters copy each command-line flag onto the matching config field.
+var flagSetters = map[string]func(f *config.File, v flag.Value){
+	"port":             func(f *config.File, v flag.Value) { f.Listen.Port = getter[int](v) },
+	"host":             func(f *config.File, v flag.Value) { f.Listen.Host = v.String() },
+	"dir":              func(f *config.File, v flag.Value) { f.Workspace = v.String() },
+	"town":             func(f *config.File, v flag.Value) { f.Town = v.String() },
+	"town-refresh":     func(f *config.File, v flag.Value) { f.Intervals.TownRefresh = getter[time.Duration](v) },
+	"write-enabled":    func(f *config.File, v flag.Value) { f.Write.Enabled = getter[bool](v) },
+	"audit-log":        func(f *config.File, v flag.Value) { f.Write.AuditLog = v.String() },
+	"data-dir":         func(f *config.File, v flag.Value) { dir := v.String(); f.DataDir = &dir },
+	"sample-interval":  func(f *config.File, v flag.Value) { f.Intervals.Sample = getter[time.Duration](v) },
+	"retention":        func(f *config.File, v flag.Value) { f.Intervals.Retention = getter[time.Duration](v) },
+	"token-file":       func(f *config.File, v flag.Value) { f.Auth.TokenFile = v.String() },
+	"tls-cert":         func(f *config.File, v flag.Value) { f.TLS.Cert = v.String() },
+	"tls-key":          func(f *config.File, v flag.Value) { f.TLS.Key = v.String() },
+	"client-ca":        func(f *config.File, v flag.Value) { f.TLS.ClientCA = v.String() },
+	"socket":           func(f *config.File, v flag.Value) { f.Listen.Socket = v.String() },
+	"socket-mode":      func(f *config.File, v flag.Value) { f.Listen.SocketMode = v.String() },
+	"socket-owner":     func(f *config.File, v flag.Value) { f.Listen.SocketOwner = v.String() },
+	"no-tcp":           func(f *config.File, v flag.Value) { f.Listen.DisableTCP = getter[bool](v) },
+	"cors-origins":     func(f *config.File, v flag.Value) { f.CORSOrigins = config.SplitList(v.String()) },
+	"sse-heartbeat":    func(f *config.File, v flag.Value) { f.Intervals.SSEHeartbeat = getter[time.Duration](v) },
+	"shutdown-timeout": func(f *config.File, v flag.Value) { f.Intervals.ShutdownTimeout = getter[time.Duration](v) },
+	"log-level":        func(f *config.File, v flag.Value) { f.Log.Level = v.String() },
+}
+
+// getter returns the typed value of a standard library flag.
+func getter[T any](v flag.Value) T {
+	return v.(flag.Getter).Get().(T)
+}
+
+// loadConfig layers the settings: built-in defaults, then the config file
+// at path (if any), then GVID_* environment variables, then the flags set
+// explicitly in fs (nil skips flags).
+func loadConfig(path string, fs *flag.FlagSet) (*config.File, api.Config, error) {
+	file := &config.File{}
+	if path != "" {
+		var err error
+		if file, err = config.Load(path); err != nil {
+			return nil, api.Config{}, err
+		}
+	}
+	if file.DataDir == nil {
+		dir := defaultDataDir()
+		file.DataDir = &dir
+	}
+
+	if err := file.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, api.Config{}, err
+	}
+
+	if fs != nil {
+		fs.Visit(func(fl *flag.Flag) {
+			if set, ok := flagSetters[fl.Name]; ok {
+				set(file, fl.Value)
+			}
+		})
+	}
+
+	cfg, err := file.APIConfig()
+	if err != nil {
+		return nil, api.Config{}, err
+	}
+	cfg.Version = version
+	return file, cfg, nil
+}
+
+// runConfig implements "gvid config validate [--config path]".
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "usage: gvid config validate [--config path]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("gvid config validate", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("GVID_CONFIG"), "YAML config file to check (default: $GVID_CONFIG)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	_, cfg, err := loadConfig(*path, nil)
+	if err == nil {
+		err = api.CheckTLS(cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+
+	source := *path
+	if source == "" {
+		source = "defaults and environment"
+	}
+	fmt.Printf("configuration OK (%s)\n", source)
+	return 0
+}
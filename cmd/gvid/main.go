@@ -3,26 +3,57 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/api"
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/config"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
 )
 
 // version is set by goreleaser ldflags at build time
 var version = "dev"
 
 func main() {
-	// Parse flags
-	port := flag.Int("port", 7070, "HTTP server port")
-	host := flag.String("host", "localhost", "HTTP server host")
-	workDir := flag.String("dir", "", "Working directory (default: current directory)")
-	townRoot := flag.String("town", "", "Gas Town workspace root (default: ~/gt)")
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(os.Args[2:]))
+	}
+
+	// Parse flags. Flags given on the command line override the config
+	// file and GVID_* environment variables.
+	configPath := flag.String("config", os.Getenv("GVID_CONFIG"), "YAML config file, reloaded on SIGHUP (default: $GVID_CONFIG)")
+	flag.Int("port", 7070, "HTTP server port")
+	flag.String("host", "localhost", "HTTP server host")
+	flag.String("dir", "", "Working directory (default: current directory)")
+	flag.String("town", "", "Gas Town workspace root (default: ~/gt)")
+	flag.Duration("town-refresh", gastown.DefaultRefreshInterval, "How often to rescan the Gas Town workspace")
+	flag.Bool("write-enabled", false, "Allow actions that change the town (send mail, nudge agents)")
+	flag.String("audit-log", "", "File to append the action audit log to (default: server log)")
+	flag.String("data-dir", defaultDataDir(), "Directory for persisted agent history and metrics (empty: keep in memory)")
+	flag.Duration("sample-interval", api.DefaultSampleInterval, "How often to sample metric series")
+	flag.Duration("retention", api.DefaultRetention, "How long to keep metric samples")
+	flag.String("token-file", "", "File of API bearer tokens, one \"<token> <scope> [name]\" per line (default: no authentication)")
+	flag.String("tls-cert", "", "PEM certificate to serve HTTPS with (reloaded on SIGHUP)")
+	flag.String("tls-key", "", "PEM private key for --tls-cert")
+	flag.String("client-ca", "", "PEM CA bundle; require client certificates signed by it")
+	flag.String("socket", "", "Unix socket path to listen on, in addition to TCP")
+	flag.String("socket-mode", "0600", "Permissions of the Unix socket (octal)")
+	flag.String("socket-owner", "", "Owner of the Unix socket as user[:group]")
+	flag.Bool("no-tcp", false, "Do not listen on TCP; serve only on --socket")
+	flag.String("cors-origins", "http://localhost:5173", "Comma-separated origins allowed to call the API from a browser")
+	flag.Duration("sse-heartbeat", api.DefaultSSEHeartbeat, "How often to send heartbeats on event streams")
+	flag.Duration("shutdown-timeout", config.DefaultShutdownTimeout, "How long to wait for in-flight requests on shutdown")
+	flag.String("log-level", "info", "Log level: debug, info, warn or error (debug logs every bd, gt and tmux call)")
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	flag.Parse()
 
@@ -31,32 +62,99 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Create beads adapter
-	adapter := beads.NewCLIAdapter(*workDir)
+	// Log JSON lines to stderr; the level follows the config on SIGHUP
+	var logLevel slog.LevelVar
+	slog.SetDefault(logging.New(os.Stderr, &logLevel))
 
-	// Create server config
-	config := api.DefaultConfig()
-	config.Port = *port
-	config.Host = *host
-	config.Version = version
-	config.TownRoot = *townRoot
+	file, cfg, err := loadConfig(*configPath, flag.CommandLine)
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+	logLevel.Set(file.LogLevel())
+	if *configPath != "" {
+		slog.Info("Loaded configuration", "path", *configPath)
+	}
+	if cfg.Tokens != nil {
+		slog.Info("API authentication enabled", "tokens", len(cfg.Tokens))
+	} else if !cfg.DisableTCP && cfg.Host != "localhost" && cfg.Host != "127.0.0.1" && cfg.ClientCA == "" {
+		slog.Warn("Listening without a token file; the API is unauthenticated", "host", cfg.Host)
+	}
+
+	// Create beads adapter
+	adapter := beads.NewCLIAdapter(file.Workspace)
 
 	// Create and start server
-	server := api.NewServer(config, adapter)
+	server := api.NewServer(cfg, adapter)
+
+	// Reload configuration and certificates on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		for range hup {
+			newFile, newCfg, err := loadConfig(*configPath, flag.CommandLine)
+			if err != nil {
+				slog.Warn("Config reload failed; keeping the current settings", "error", err)
+				continue
+			}
+			if newFile.Workspace != file.Workspace {
+				slog.Warn("Some changes take effect after a restart", "settings", "workspace")
+			}
+			logLevel.Set(newFile.LogLevel())
+			if err := server.Reload(newCfg); err != nil {
+				slog.Warn("Keeping the current certificate", "error", err)
+			}
+			slog.Info("Configuration reloaded")
+		}
+	}()
+
+	// Start server
+	slog.Info("Gastown Viewer Intent daemon", "version", version)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Start()
+	}()
 
 	// Handle graceful shutdown
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
 
+	shutdownTimeout := file.ShutdownTimeout()
+	select {
+	case err := <-serveErr:
+		fatal("Server error", err)
+	case sig := <-done:
+		slog.Info("Shutting down", "signal", sig.String(), "timeout", shutdownTimeout.String())
+	}
+
+	// A second signal skips the wait
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 	go func() {
 		<-done
-		log.Println("Shutting down...")
-		os.Exit(0)
+		cancel()
 	}()
 
-	// Start server
-	log.Printf("Gastown Viewer Intent daemon v%s", version)
-	if err := server.Start(); err != nil {
-		log.Fatalf("Server error: %v", err)
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("Shutdown incomplete", "error", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server error", "error", err)
+	}
+	slog.Info("Stopped")
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// defaultDataDir returns ~/.gvid, or "" if the home directory is unknown.
+func defaultDataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
 	}
+	return filepath.Join(home, ".gvid")
 }
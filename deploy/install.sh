@@ -14,6 +14,15 @@ fi
 sudo mkdir -p /var/lib/gvid
 sudo chown gvid:gvid /var/lib/gvid
 
+# Generate an admin API token on first install
+if [ ! -f /etc/gvid/tokens ]; then
+    sudo mkdir -p /etc/gvid
+    echo "$(openssl rand -hex 32) admin admin" | sudo tee /etc/gvid/tokens >/dev/null
+    sudo chown root:gvid /etc/gvid/tokens
+    sudo chmod 0640 /etc/gvid/tokens
+    echo "Generated an admin API token in /etc/gvid/tokens"
+fi
+
 # Copy binaries
 sudo cp bin/gvid /usr/local/bin/
 sudo cp bin/gvi-tui /usr/local/bin/
@@ -21,7 +30,9 @@ sudo chmod +x /usr/local/bin/gvid /usr/local/bin/gvi-tui
 
 # Install systemd service
 sudo cp deploy/gvid.service /etc/systemd/system/
+sudo cp deploy/gvid.socket /etc/systemd/system/
 sudo systemctl daemon-reload
 sudo systemctl enable gvid
 
 echo "Done. Start with: sudo systemctl start gvid"
+echo "For socket activation on /run/gvid/gvid.sock instead: sudo systemctl enable --now gvid.socket"
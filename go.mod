@@ -3,9 +3,11 @@ module github.com/intent-solutions-io/gastown-viewer-intent
 go 1.24.0
 
 require (
+	github.com/andybalholm/brotli v1.2.6
 	github.com/charmbracelet/bubbles v0.21.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
+)
+
+// AuditEntry records one state-changing action taken through the API.
+type AuditEntry struct {
+	Time    time.Time              `json:"time"`
+	Action  string                 `json:"action"`
+	Target  string                 `json:"target"`
+	Remote  string                 `json:"remote"`
+	Actor   string                 `json:"actor,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+
+	// RequestID ties the entry to the request's access log line.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AuditLog appends entries as JSON lines.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer // nil logs entries through slog
+}
+
+// NewAuditLog opens path for appending. An empty path logs entries through
+// the server log instead.
+func NewAuditLog(path string) (*AuditLog, error) {
+	if path == "" {
+		return &AuditLog{}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{w: f}, nil
+}
+
+// Record writes an entry, filling in the time if unset.
+func (a *AuditLog) Record(entry AuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	if a.w == nil {
+		slog.Info("audit", "entry", entry)
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		slog.Error("Failed to encode audit entry", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(data, '\n')); err != nil {
+		slog.Error("Failed to write audit entry", "error", err)
+	}
+}
+
+// Close closes the underlying file, if any.
+func (a *AuditLog) Close() error {
+	if c, ok := a.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// audit records an action taken by the client of r.
+func (s *Server) audit(r *http.Request, action, target string, details map[string]interface{}, err error) {
+	entry := AuditEntry{
+		Action:    action,
+		Target:    target,
+		Remote:    r.RemoteAddr,
+		Details:   details,
+		RequestID: logging.RequestID(r.Context()),
+	}
+	if token, ok := tokenFromContext(r.Context()); ok {
+		entry.Actor = token.Name
+	} else if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		entry.Actor = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.auditLog.Record(entry)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Scope is the level of access granted to an API token.
+type Scope string
+
+const (
+	// ScopeRead allows GET requests, including event streams.
+	ScopeRead Scope = "read"
+	// ScopeAdmin additionally allows requests that change state.
+	ScopeAdmin Scope = "admin"
+)
+
+// Token is an API credential loaded from the token file.
+type Token struct {
+	Name  string
+	Scope Scope
+}
+
+// allows reports whether a token with scope s may use a request needing need.
+func (s Scope) allows(need Scope) bool {
+	return s == ScopeAdmin || s == need
+}
+
+// Tokens maps the SHA-256 of each secret to the token it identifies, so
+// lookups do not compare secrets byte by byte.
+type Tokens map[[sha256.Size]byte]Token
+
+// LoadTokens reads a token file. Each non-empty line that is not a
+// comment holds a secret, a scope (read or admin) and an optional name:
+//
+//	4f9c...e1  admin  ops
+//	7a02...9b  read   grafana
+func LoadTokens(path string) (Tokens, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer f.Close()
+
+	tokens := make(Tokens)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%s:%d: expected \"<token> <scope> [name]\"", path, lineNo)
+		}
+
+		scope := Scope(fields[1])
+		if scope != ScopeRead && scope != ScopeAdmin {
+			return nil, fmt.Errorf("%s:%d: unknown scope %q (want read or admin)", path, lineNo, fields[1])
+		}
+
+		name := fmt.Sprintf("line %d", lineNo)
+		if len(fields) == 3 {
+			name = fields[2]
+		}
+		tokens[sha256.Sum256([]byte(fields[0]))] = Token{Name: name, Scope: scope}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%s: no tokens defined", path)
+	}
+	return tokens, nil
+}
+
+// Lookup returns the token for secret, if any.
+func (t Tokens) Lookup(secret string) (Token, bool) {
+	token, ok := t[sha256.Sum256([]byte(secret))]
+	return token, ok
+}
+
+type tokenContextKey struct{}
+
+// tokenFromContext returns the token that authenticated the request, if any.
+func tokenFromContext(ctx context.Context) (Token, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(Token)
+	return token, ok
+}
+
+// authMiddleware requires a bearer token on API requests when tokens are
+// configured. GET and HEAD need the read scope; anything else needs admin.
+// The web UI's static files and the health check stay public, as does
+// everything on the Unix socket, whose file mode controls access.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens := s.settings().tokens
+		if tokens == nil || !requiresAuth(r) || isUnixConn(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret := bearerToken(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gvid"`)
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "bearer token required")
+			return
+		}
+
+		token, ok := tokens.Lookup(secret)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gvid", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid token")
+			return
+		}
+
+		need := ScopeRead
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			need = ScopeAdmin
+		}
+		if !token.Scope.allows(need) {
+			writeJSON(w, http.StatusForbidden, ErrorResponse{
+				Error: fmt.Sprintf("%s scope required", need),
+				Code:  "INSUFFICIENT_SCOPE",
+				Details: map[string]interface{}{
+					"required": need,
+					"scope":    token.Scope,
+				},
+			})
+			return
+		}
+
+		authed := r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token))
+		next.ServeHTTP(w, authed)
+		// Pass the matched route back out to the logging middleware
+		r.Pattern = authed.Pattern
+	})
+}
+
+// requiresAuth reports whether r targets a protected endpoint.
+func requiresAuth(r *http.Request) bool {
+	path := r.URL.Path
+	if path == "/api/v1/health" {
+		return false
+	}
+	return strings.HasPrefix(path, "/api/") || path == "/metrics"
+}
+
+// bearerToken returns the token from the Authorization header. Event
+// streams may pass it as ?token= instead, since EventSource cannot set
+// headers.
+func bearerToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if isEventStream(r) {
+		return r.URL.Query().Get("token")
+	}
+	return ""
+}
+
+// isEventStream reports whether r targets an SSE endpoint.
+func isEventStream(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		(r.URL.Path == "/api/v1/events" || strings.HasSuffix(r.URL.Path, "/stream"))
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedResources bounds the validators remembered for Last-Modified.
+// Past it the tracker starts over, which only makes Last-Modified newer.
+const maxTrackedResources = 1024
+
+// validators remembers when each resource's content last changed, so
+// Last-Modified stays put while its ETag does.
+type validators struct {
+	mu      sync.Mutex
+	entries map[string]validator
+}
+
+type validator struct {
+	etag     string
+	modified time.Time
+}
+
+func newValidators() *validators {
+	return &validators{entries: make(map[string]validator)}
+}
+
+// modified returns when resource first had etag, recording now if its
+// content has changed since the last call.
+func (v *validators) modified(resource, etag string, now time.Time) time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if e, ok := v.entries[resource]; ok && e.etag == etag {
+		return e.modified
+	}
+	if len(v.entries) >= maxTrackedResources {
+		clear(v.entries)
+	}
+	now = now.UTC().Truncate(time.Second)
+	v.entries[resource] = validator{etag: etag, modified: now}
+	return now
+}
+
+// conditional wraps a GET handler with content-hash validators. The
+// response is buffered and given a weak ETag and a Last-Modified time;
+// requests whose If-None-Match or If-Modified-Since still match get
+// 304 Not Modified without a body. Error responses pass through unchanged.
+func (s *Server) conditional(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next(buf, r)
+
+		for k, v := range buf.header {
+			w.Header()[k] = v
+		}
+		if buf.status != http.StatusOK {
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(buf.body.Bytes())
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		modified := s.validators.modified(r.URL.RequestURI(), etag, time.Now())
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Header().Set("Cache-Control", "no-cache")
+
+		if notModified(r, etag, modified) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.body.Bytes())
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when no
+// If-None-Match is given, as RFC 9110 orders them.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !modified.After(t)
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match list contains etag, using
+// weak comparison.
+func etagMatches(list, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse collects a handler's response so it can be hashed
+// before anything is sent.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+)
+
+func newBoardServer(t *testing.T) (*Server, *beads.MockExecutor) {
+	t.Helper()
+	config := testConfig()
+	executor := beads.NewMockExecutor()
+	executor.SetResponse("status", []byte("ok"))
+	executor.SetResponse("list --json", []byte(`[{"id": "bd-1", "title": "One", "status": "open"}]`))
+	return NewServer(config, beads.NewCLIAdapterWithExecutor("", executor)), executor
+}
+
+func TestConditionalGet(t *testing.T) {
+	server, executor := newBoardServer(t)
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/board", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("", "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+	etag := first.Header().Get("ETag")
+	modified := first.Header().Get("Last-Modified")
+	if !strings.HasPrefix(etag, `W/"`) || modified == "" {
+		t.Fatalf("Expected weak ETag and Last-Modified, got %q and %q", etag, modified)
+	}
+
+	w := get("If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected empty 304 for matching ETag, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("Expected 304 to repeat the ETag, got %q", w.Header().Get("ETag"))
+	}
+
+	if w := get("If-None-Match", `"other", `+strings.TrimPrefix(etag, "W/")); w.Code != http.StatusNotModified {
+		t.Errorf("Expected weak comparison to match strong form in a list, got %d", w.Code)
+	}
+	if w := get("If-Modified-Since", modified); w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for If-Modified-Since, got %d", w.Code)
+	}
+	if w := get("If-None-Match", `W/"stale"`); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for stale ETag, got %d", w.Code)
+	}
+
+	// Changed content gets a new ETag and the old one no longer matches
+	executor.SetResponse("list --json", []byte(`[{"id": "bd-1", "title": "One", "status": "closed"}]`))
+	w = get("If-None-Match", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 with a new ETag after a change, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestConditionalGet_ErrorsPassThrough(t *testing.T) {
+	server, executor := newBoardServer(t)
+	executor.SetError("status", &beads.NotInitializedError{})
+
+	req := httptest.NewRequest("GET", "/api/v1/board", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") != "" {
+		t.Error("Expected no ETag on an error response")
+	}
+}
+
+func TestValidators(t *testing.T) {
+	v := newValidators()
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 500, time.UTC)
+
+	if got := v.modified("/a", "x", t0); !got.Equal(t0.Truncate(time.Second)) {
+		t.Errorf("Expected first sighting time, got %s", got)
+	}
+	if got := v.modified("/a", "x", t0.Add(time.Hour)); !got.Equal(t0.Truncate(time.Second)) {
+		t.Errorf("Expected unchanged content to keep its time, got %s", got)
+	}
+	if got := v.modified("/a", "y", t0.Add(time.Hour)); !got.Equal(t0.Add(time.Hour).Truncate(time.Second)) {
+		t.Errorf("Expected changed content to move the time, got %s", got)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	server, _ := newBoardServer(t)
+
+	plain := httptest.NewRecorder()
+	server.Handler().ServeHTTP(plain, httptest.NewRequest("GET", "/api/v1/board", nil))
+	if plain.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("Expected no encoding without Accept-Encoding")
+	}
+
+	tests := []struct {
+		accept string
+		want   string
+		reader func(io.Reader) (io.Reader, error)
+	}{
+		{"gzip", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"gzip, deflate, br", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+		{"br;q=0.5, gzip", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"br;q=0, identity", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/board", nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			w := httptest.NewRecorder()
+			server.Handler().ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Expected encoding %q, got %q", tt.want, got)
+			}
+			if !strings.Contains(w.Header().Get("Vary"), "Accept-Encoding") {
+				t.Error("Expected Vary: Accept-Encoding")
+			}
+			if tt.reader == nil {
+				return
+			}
+			r, err := tt.reader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Failed to decode body: %v", err)
+			}
+			if string(body) != plain.Body.String() {
+				t.Errorf("Decoded body differs:\n%s\nwant:\n%s", body, plain.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompression_SkipsEventStreams(t *testing.T) {
+	server, _ := newBoardServer(t)
+	go server.sse.Start()
+	defer server.sse.Stop()
+
+	srv := httptest.NewServer(server.Handler())
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL+"/api/v1/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected event stream, got %q", resp.Header.Get("Content-Type"))
+	}
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		t.Errorf("Expected event stream to be sent uncompressed, got %q", enc)
+	}
+}
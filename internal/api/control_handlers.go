@@ -0,0 +1,265 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+)
+
+// maxControlBody limits the size of write request bodies.
+const maxControlBody = 64 << 10
+
+// requireWrite writes a 403 and returns false unless write mode is enabled.
+func (s *Server) requireWrite(w http.ResponseWriter) bool {
+	if !s.settings().writeEnabled {
+		writeError(w, http.StatusForbidden, "WRITE_DISABLED",
+			"gvid is read-only; start it with --write-enabled to allow actions")
+		return false
+	}
+	return true
+}
+
+// decodeBody decodes a JSON request body into v, writing a 400 on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxControlBody)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return false
+	}
+	return true
+}
+
+// SendMailResponse is the response for POST /api/v1/town/mail/{address}.
+type SendMailResponse struct {
+	Sent    bool   `json:"sent"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+// MailSender is the identity mail sent through the API goes out as. The
+// request body cannot choose the sender; the audit log records who asked.
+const MailSender = "gvid/overseer"
+
+// handleSendMail handles POST /api/v1/town/mail/{address}.
+func (s *Server) handleSendMail(w http.ResponseWriter, r *http.Request) {
+	if !s.requireWrite(w) {
+		return
+	}
+
+	ctx := r.Context()
+	address := r.PathValue("address")
+
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "address required")
+		return
+	}
+
+	var msg gastown.Message
+	if !decodeBody(w, r, &msg) {
+		return
+	}
+	msg.To = address
+	msg.From = MailSender
+	if msg.Subject == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "subject required")
+		return
+	}
+
+	if _, err := s.gtAdapter.Agent(ctx, address); err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	err := s.gtControl.SendMail(ctx, msg)
+	s.audit(r, "mail.send", address, map[string]interface{}{
+		"from":     msg.From,
+		"subject":  msg.Subject,
+		"priority": msg.Priority,
+		"type":     msg.Type,
+	}, err)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "MAIL_SEND_FAILED", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SendMailResponse{
+		Sent:    true,
+		To:      address,
+		Subject: msg.Subject,
+	})
+}
+
+// NudgeRequest is the body of a nudge request.
+type NudgeRequest struct {
+	Message string `json:"message"`
+}
+
+// NudgeResponse is the response for POST /api/v1/town/agents/{address}/nudge.
+type NudgeResponse struct {
+	Nudged  bool   `json:"nudged"`
+	Address string `json:"address"`
+	Session string `json:"session"`
+}
+
+// handleNudge handles POST /api/v1/town/agents/{address}/nudge.
+func (s *Server) handleNudge(w http.ResponseWriter, r *http.Request) {
+	if !s.requireWrite(w) {
+		return
+	}
+
+	ctx := r.Context()
+	address := r.PathValue("address")
+
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "address required")
+		return
+	}
+
+	var req NudgeRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Message == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "message required")
+		return
+	}
+
+	agent, err := s.gtAdapter.Agent(ctx, address)
+	if err != nil {
+		handleGastownError(w, err)
+		return
+	}
+	if agent.Status == gastown.StatusOffline {
+		writeError(w, http.StatusConflict, "SESSION_OFFLINE",
+			fmt.Sprintf("agent %s has no running tmux session", address))
+		return
+	}
+
+	err = s.gtControl.Nudge(ctx, agent, req.Message)
+	s.audit(r, "agent.nudge", address, map[string]interface{}{
+		"session": agent.Session,
+		"message": req.Message,
+	}, err)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "NUDGE_FAILED", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NudgeResponse{
+		Nudged:  true,
+		Address: address,
+		Session: agent.Session,
+	})
+}
+
+// Agent lifecycle actions.
+const (
+	actionStart   = "start"
+	actionStop    = "stop"
+	actionRestart = "restart"
+)
+
+// LifecycleResponse reports the outcome of a start, stop or restart.
+type LifecycleResponse struct {
+	Address        string `json:"address"`
+	Action         string `json:"action"`
+	Changed        bool   `json:"changed"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	Session        string `json:"session,omitempty"`
+}
+
+// handleAgentStart handles POST /api/v1/town/agents/{address}/start.
+func (s *Server) handleAgentStart(w http.ResponseWriter, r *http.Request) {
+	s.agentLifecycle(w, r, actionStart)
+}
+
+// handleAgentStop handles POST /api/v1/town/agents/{address}/stop.
+func (s *Server) handleAgentStop(w http.ResponseWriter, r *http.Request) {
+	s.agentLifecycle(w, r, actionStop)
+}
+
+// handleAgentRestart handles POST /api/v1/town/agents/{address}/restart.
+func (s *Server) handleAgentRestart(w http.ResponseWriter, r *http.Request) {
+	s.agentLifecycle(w, r, actionRestart)
+}
+
+// agentLifecycle runs a lifecycle action against an agent session. Stop and
+// restart interrupt running work, so they require ?confirm=true. Actions
+// are idempotent: starting a running agent or stopping an offline one
+// succeeds with changed=false and emits no event.
+func (s *Server) agentLifecycle(w http.ResponseWriter, r *http.Request, action string) {
+	if !s.requireWrite(w) {
+		return
+	}
+
+	ctx := r.Context()
+	address := r.PathValue("address")
+
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "address required")
+		return
+	}
+
+	agent, err := s.gtAdapter.Agent(ctx, address)
+	if err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	confirmed := r.URL.Query().Get("confirm") == "true"
+	if action != actionStart && !confirmed {
+		writeJSON(w, http.StatusConflict, ErrorResponse{
+			Error: fmt.Sprintf("%s of %s requires confirmation", action, address),
+			Code:  "CONFIRMATION_REQUIRED",
+			Details: map[string]interface{}{
+				"confirm": "repeat the request with ?confirm=true",
+				"status":  agent.Status,
+			},
+		})
+		return
+	}
+
+	var changed bool
+	switch action {
+	case actionStart:
+		changed, err = s.gtControl.StartAgent(ctx, agent)
+	case actionStop:
+		changed, err = s.gtControl.StopAgent(ctx, agent)
+	case actionRestart:
+		err = s.gtControl.RestartAgent(ctx, agent)
+		changed = err == nil
+	}
+
+	s.audit(r, "agent."+action, address, map[string]interface{}{
+		"session": agent.Session,
+		"changed": changed,
+	}, err)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "LIFECYCLE_FAILED", err.Error())
+		return
+	}
+
+	status := gastown.StatusActive
+	if action == actionStop {
+		status = gastown.StatusOffline
+	}
+	if !changed {
+		status = agent.Status
+	}
+
+	if changed {
+		s.NotifyAgentStatus(address, string(status), string(agent.Status), action)
+	}
+
+	writeJSON(w, http.StatusOK, LifecycleResponse{
+		Address:        address,
+		Action:         action,
+		Changed:        changed,
+		Status:         string(status),
+		PreviousStatus: string(agent.Status),
+		Session:        agent.Session,
+	})
+}
@@ -1,22 +1,83 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
 )
 
+// writeTownJSON writes a 200 response for data served from the town
+// snapshot, reporting when the snapshot was taken and its age in seconds.
+func (s *Server) writeTownJSON(w http.ResponseWriter, data interface{}) {
+	if taken := s.gtCache.SnapshotTime(); !taken.IsZero() {
+		w.Header().Set("X-Snapshot-Time", taken.UTC().Format(time.RFC3339))
+		w.Header().Set("X-Snapshot-Age", strconv.Itoa(int(time.Since(taken).Seconds())))
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// handleGastownError converts Gas Town adapter errors to HTTP responses.
+func handleGastownError(w http.ResponseWriter, err error) {
+	status, code := gastownErrorCode(err)
+	writeError(w, status, code, err.Error())
+}
+
+// gastownErrorCode returns the HTTP status and error code for a Gas Town
+// adapter error.
+func gastownErrorCode(err error) (int, string) {
+	var notFound *gastown.NotFoundError
+	var cmdNotFound *gastown.CommandNotFoundError
+	var cmdErr *gastown.CommandError
+	switch {
+	case gastown.IsTownNotFoundError(err):
+		return http.StatusNotFound, "TOWN_NOT_FOUND"
+	case gastown.IsRigNotFoundError(err):
+		return http.StatusNotFound, "RIG_NOT_FOUND"
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, strings.ToUpper(notFound.Kind) + "_NOT_FOUND"
+	case gastown.IsGTNotFoundError(err):
+		return http.StatusServiceUnavailable, "GT_NOT_FOUND"
+	case gastown.IsGTExecutionError(err):
+		return http.StatusBadGateway, "GT_ERROR"
+	case errors.As(err, &cmdNotFound):
+		return http.StatusServiceUnavailable, strings.ToUpper(cmdNotFound.Name) + "_NOT_FOUND"
+	case errors.As(err, &cmdErr):
+		return http.StatusBadGateway, strings.ToUpper(cmdErr.Name) + "_ERROR"
+	case gastown.IsParseError(err):
+		return http.StatusInternalServerError, "PARSE_ERROR"
+	default:
+		return http.StatusInternalServerError, "GASTOWN_ERROR"
+	}
+}
+
+// paneErrorCode returns the HTTP status and error code for a failed pane
+// capture. A failing tmux capture-pane is PANE_CAPTURE_FAILED; anything
+// else, such as a missing tmux, maps like other Gas Town errors.
+func paneErrorCode(err error) (int, string) {
+	if gastown.IsCommandError(err) {
+		return http.StatusBadGateway, "PANE_CAPTURE_FAILED"
+	}
+	return gastownErrorCode(err)
+}
+
 // handleTownStatus handles GET /api/v1/town/status.
 func (s *Server) handleTownStatus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	status, err := s.gtAdapter.Status(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, status)
+	s.writeTownJSON(w, status)
 }
 
 // handleTown handles GET /api/v1/town.
@@ -25,11 +86,17 @@ func (s *Server) handleTown(w http.ResponseWriter, r *http.Request) {
 
 	town, err := s.gtAdapter.Town(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, town)
+	s.writeTownJSON(w, town)
+}
+
+// RigsResponse is the response for GET /api/v1/town/rigs.
+type RigsResponse struct {
+	Rigs  []gastown.Rig `json:"rigs"`
+	Total int           `json:"total"`
 }
 
 // handleRigs handles GET /api/v1/town/rigs.
@@ -38,13 +105,13 @@ func (s *Server) handleRigs(w http.ResponseWriter, r *http.Request) {
 
 	rigs, err := s.gtAdapter.Rigs(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"rigs":  rigs,
-		"total": len(rigs),
+	s.writeTownJSON(w, RigsResponse{
+		Rigs:  rigs,
+		Total: len(rigs),
 	})
 }
 
@@ -60,11 +127,44 @@ func (s *Server) handleRig(w http.ResponseWriter, r *http.Request) {
 
 	rig, err := s.gtAdapter.Rig(ctx, name)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "RIG_NOT_FOUND", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, rig)
+	s.writeTownJSON(w, rig)
+}
+
+// handleMergeQueue handles GET /api/v1/town/rigs/{name}/merge-queue.
+func (s *Server) handleMergeQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := r.PathValue("name")
+
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "rig name required")
+		return
+	}
+
+	if _, err := s.gtAdapter.Rig(ctx, name); err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	queue, err := s.gtAdapter.MergeQueue(ctx, name)
+	if err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, queue)
+}
+
+// AgentsResponse is the response for GET /api/v1/town/agents.
+type AgentsResponse struct {
+	Agents     []gastown.Agent `json:"agents"`
+	Total      int             `json:"total"`
+	Active     int             `json:"active"`
+	Offline    int             `json:"offline"`
+	UnreadMail int             `json:"unread_mail"`
 }
 
 // handleAgents handles GET /api/v1/town/agents.
@@ -73,7 +173,7 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 
 	agents, err := s.gtAdapter.Agents(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
@@ -87,21 +187,176 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"agents":  agents,
-		"total":   len(agents),
-		"active":  len(active),
-		"offline": len(offline),
+	unread := 0
+	for _, a := range agents {
+		unread += a.UnreadMail
+	}
+
+	s.writeTownJSON(w, AgentsResponse{
+		Agents:     agents,
+		Total:      len(agents),
+		Active:     len(active),
+		Offline:    len(offline),
+		UnreadMail: unread,
 	})
 }
 
+const (
+	paneStreamInterval    = 2 * time.Second
+	paneStreamMinInterval = 500 * time.Millisecond
+)
+
+// handleAgentPane handles GET /api/v1/town/agents/{address}/pane.
+// The address must be URL-encoded (e.g. gastown%2Fnux).
+func (s *Server) handleAgentPane(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, lines, ansi, ok := s.paneRequest(w, r)
+	if !ok {
+		return
+	}
+
+	pane, err := s.gtAdapter.Pane(ctx, agent, lines, ansi)
+	if err != nil {
+		status, code := paneErrorCode(err)
+		writeError(w, status, code, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pane)
+}
+
+// handleAgentPaneStream handles GET /api/v1/town/agents/{address}/pane/stream.
+// It polls the agent's tmux pane and sends a "pane" SSE event whenever the
+// captured content changes.
+func (s *Server) handleAgentPaneStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, lines, ansi, ok := s.paneRequest(w, r)
+	if !ok {
+		return
+	}
+
+	interval := paneStreamInterval
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAM", "invalid interval: "+v)
+			return
+		}
+		if d < paneStreamMinInterval {
+			d = paneStreamMinInterval
+		}
+		interval = d
+	}
+
+	stream, ok := newSSEStream(w)
+	if !ok {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	// Send the headers now so the client sees the stream open
+	if err := stream.write(nil); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// A comment line keeps proxies from closing a stream whose pane is idle.
+	heartbeat := time.NewTicker(s.sse.heartbeat)
+	defer heartbeat.Stop()
+
+	var last string
+	for {
+		pane, err := s.gtAdapter.Pane(ctx, agent, lines, ansi)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			_, code := paneErrorCode(err)
+			data, _ := json.Marshal(ErrorResponse{Error: err.Error(), Code: code})
+			_ = stream.write([]byte(fmt.Sprintf("event: error\ndata: %s\n\n", data)))
+			return
+		}
+
+		if content := pane.Content(); content != last {
+			last = content
+			data, _ := json.Marshal(pane)
+			if err := stream.write([]byte(fmt.Sprintf("event: pane\ndata: %s\n\n", data))); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopping:
+			if msg, err := formatEvent(model.NewShutdownEvent()); err == nil {
+				_ = stream.write(msg)
+			}
+			return
+		case <-heartbeat.C:
+			if err := stream.write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// paneRequest resolves the agent and capture options for a pane request,
+// writing an error response and returning ok=false on failure.
+func (s *Server) paneRequest(w http.ResponseWriter, r *http.Request) (agent *gastown.Agent, lines int, ansi bool, ok bool) {
+	address := r.PathValue("address")
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "address required")
+		return nil, 0, false, false
+	}
+
+	query := r.URL.Query()
+	lines = gastown.DefaultPaneLines
+	if v := query.Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAM", "lines must be a positive integer")
+			return nil, 0, false, false
+		}
+		lines = n
+	}
+	ansi = query.Get("ansi") == "true" || query.Get("ansi") == "1"
+
+	agent, err := s.gtAdapter.Agent(r.Context(), address)
+	if err != nil {
+		handleGastownError(w, err)
+		return nil, 0, false, false
+	}
+	if agent.Status == gastown.StatusOffline {
+		writeError(w, http.StatusConflict, "SESSION_OFFLINE",
+			fmt.Sprintf("agent %s has no running tmux session", address))
+		return nil, 0, false, false
+	}
+
+	return agent, lines, ansi, true
+}
+
+// ConvoysResponse is the response for GET /api/v1/town/convoys. Failed
+// convoys count as blocked.
+type ConvoysResponse struct {
+	Convoys    []gastown.Convoy `json:"convoys"`
+	Total      int              `json:"total"`
+	InProgress int              `json:"in_progress"`
+	Pending    int              `json:"pending"`
+	Complete   int              `json:"complete"`
+	Blocked    int              `json:"blocked"`
+}
+
 // handleConvoys handles GET /api/v1/town/convoys.
 func (s *Server) handleConvoys(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	convoys, err := s.gtAdapter.Convoys(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
@@ -120,13 +375,13 @@ func (s *Server) handleConvoys(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"convoys":     convoys,
-		"total":       len(convoys),
-		"in_progress": inProgress,
-		"pending":     pending,
-		"complete":    complete,
-		"blocked":     blocked,
+	s.writeTownJSON(w, ConvoysResponse{
+		Convoys:    convoys,
+		Total:      len(convoys),
+		InProgress: inProgress,
+		Pending:    pending,
+		Complete:   complete,
+		Blocked:    blocked,
 	})
 }
 
@@ -142,11 +397,26 @@ func (s *Server) handleConvoy(w http.ResponseWriter, r *http.Request) {
 
 	convoy, err := s.gtAdapter.Convoy(ctx, id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "CONVOY_NOT_FOUND", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, convoy)
+	s.writeTownJSON(w, convoy)
+}
+
+// MailResponse is the response for GET /api/v1/town/mail and
+// GET /api/v1/town/mail/{address}.
+type MailResponse struct {
+	Messages []gastown.Message `json:"messages"`
+	Total    int               `json:"total"`
+	Unread   int               `json:"unread"`
+}
+
+// ThreadsResponse is the response for GET /api/v1/town/mail/{address}/threads.
+type ThreadsResponse struct {
+	Threads []gastown.Thread `json:"threads"`
+	Total   int              `json:"total"`
+	Unread  int              `json:"unread"`
 }
 
 // handleMail handles GET /api/v1/town/mail/{address}.
@@ -161,23 +431,128 @@ func (s *Server) handleMail(w http.ResponseWriter, r *http.Request) {
 
 	messages, err := s.gtAdapter.Mail(ctx, address)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
+		return
+	}
+
+	// gt may leave out "to" for a single inbox, so every message counts
+	unread := 0
+	for _, m := range messages {
+		if !m.Read {
+			unread++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, MailResponse{
+		Messages: messages,
+		Total:    len(messages),
+		Unread:   unread,
+	})
+}
+
+// handleMailThreads handles GET /api/v1/town/mail/{address}/threads.
+func (s *Server) handleMailThreads(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	address := r.PathValue("address")
+
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "address required")
+		return
+	}
+
+	messages, err := s.gtAdapter.Mail(ctx, address)
+	if err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	threads := gastown.GroupThreads(messages)
+	unread := 0
+	for _, t := range threads {
+		unread += t.Unread
+	}
+
+	writeJSON(w, http.StatusOK, ThreadsResponse{
+		Threads: threads,
+		Total:   len(threads),
+		Unread:  unread,
+	})
+}
+
+// handleTownMail handles GET /api/v1/town/mail.
+// Query params: type, priority, unread (true/false), limit (default 50).
+func (s *Server) handleTownMail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	limit := 50
+	if limitStr := query.Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAM", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	messages, err := s.gtAdapter.TownMail(ctx)
+	if err != nil {
+		handleGastownError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"messages": messages,
-		"total":    len(messages),
+	msgType := query.Get("type")
+	priority := query.Get("priority")
+	unreadOnly := query.Get("unread") == "true" || query.Get("unread") == "1"
+
+	filtered := []gastown.Message{}
+	unread := 0
+	for _, m := range messages {
+		if msgType != "" && m.Type != msgType {
+			continue
+		}
+		if priority != "" && m.Priority != priority {
+			continue
+		}
+		if unreadOnly && m.Read {
+			continue
+		}
+		if !m.Read {
+			unread++
+		}
+		filtered = append(filtered, m)
+	}
+
+	total := len(filtered)
+	if len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+
+	s.writeTownJSON(w, MailResponse{
+		Messages: filtered,
+		Total:    total,
+		Unread:   unread,
 	})
 }
 
+// MoleculesResponse is the response for GET /api/v1/town/molecules. Failed
+// molecules count as blocked.
+type MoleculesResponse struct {
+	Molecules  []gastown.Molecule `json:"molecules"`
+	Total      int                `json:"total"`
+	InProgress int                `json:"in_progress"`
+	Pending    int                `json:"pending"`
+	Complete   int                `json:"complete"`
+	Blocked    int                `json:"blocked"`
+}
+
 // handleMolecules handles GET /api/v1/town/molecules.
 func (s *Server) handleMolecules(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	molecules, err := s.gtAdapter.Molecules(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "GASTOWN_ERROR", err.Error())
+		handleGastownError(w, err)
 		return
 	}
 
@@ -196,13 +571,13 @@ func (s *Server) handleMolecules(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"molecules":   molecules,
-		"total":       len(molecules),
-		"in_progress": inProgress,
-		"pending":     pending,
-		"complete":    complete,
-		"blocked":     blocked,
+	s.writeTownJSON(w, MoleculesResponse{
+		Molecules:  molecules,
+		Total:      len(molecules),
+		InProgress: inProgress,
+		Pending:    pending,
+		Complete:   complete,
+		Blocked:    blocked,
 	})
 }
 
@@ -218,9 +593,34 @@ func (s *Server) handleMolecule(w http.ResponseWriter, r *http.Request) {
 
 	molecule, err := s.gtAdapter.Molecule(ctx, id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "MOLECULE_NOT_FOUND", err.Error())
+		handleGastownError(w, err)
+		return
+	}
+
+	s.writeTownJSON(w, molecule)
+}
+
+// handleFormulaStats handles GET /api/v1/town/formulas/{formula}/stats.
+func (s *Server) handleFormulaStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	formula := r.PathValue("formula")
+
+	if formula == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "formula required")
+		return
+	}
+
+	molecules, err := s.gtAdapter.Molecules(ctx)
+	if err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	stats := gastown.ComputeFormulaStats(formula, molecules, time.Now())
+	if stats.Molecules == 0 {
+		writeError(w, http.StatusNotFound, "FORMULA_NOT_FOUND", "no molecules found for formula: "+formula)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, molecule)
+	s.writeTownJSON(w, stats)
 }
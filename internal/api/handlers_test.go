@@ -1,17 +1,37 @@
 package api
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
 )
 
+// testConfig returns a config for a server without a town whose commands
+// go to a mock runner, so tests never run gt, tmux or git.
+func testConfig() Config {
+	config := DefaultConfig()
+	config.TownRoot = "/tmp/nonexistent-town"
+	config.Runner = gastown.NewMockRunner()
+	return config
+}
+
 func TestHealthHandler(t *testing.T) {
 	// Create server with mock adapter
-	config := DefaultConfig()
+	config := testConfig()
 	config.TownRoot = "/tmp/nonexistent"
 	adapter := beads.NewCLIAdapter("")
 
@@ -39,8 +59,7 @@ func TestHealthHandler(t *testing.T) {
 }
 
 func TestTownStatusHandler(t *testing.T) {
-	config := DefaultConfig()
-	config.TownRoot = "/tmp/nonexistent-town"
+	config := testConfig()
 	adapter := beads.NewCLIAdapter("")
 
 	server := NewServer(config, adapter)
@@ -66,7 +85,7 @@ func TestTownStatusHandler(t *testing.T) {
 }
 
 func TestCORSMiddleware(t *testing.T) {
-	config := DefaultConfig()
+	config := testConfig()
 	config.CORSOrigins = []string{"http://localhost:5173"}
 	adapter := beads.NewCLIAdapter("")
 
@@ -84,7 +103,7 @@ func TestCORSMiddleware(t *testing.T) {
 }
 
 func TestCORSPreflight(t *testing.T) {
-	config := DefaultConfig()
+	config := testConfig()
 	config.CORSOrigins = []string{"http://localhost:5173"}
 	adapter := beads.NewCLIAdapter("")
 
@@ -100,3 +119,637 @@ func TestCORSPreflight(t *testing.T) {
 		t.Errorf("Expected status 204 for preflight, got %d", w.Code)
 	}
 }
+
+func TestAgentPaneNotFound(t *testing.T) {
+	config := testConfig()
+	adapter := beads.NewCLIAdapter("")
+
+	server := NewServer(config, adapter)
+
+	req := httptest.NewRequest("GET", "/api/v1/town/agents/gastown%2Fnux/pane", nil)
+	w := httptest.NewRecorder()
+
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestAgentPaneErrors(t *testing.T) {
+	townRoot := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(townRoot, "mayor"),
+		filepath.Join(townRoot, "gastown", "polecats", "nux"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner := gastown.NewMockRunner()
+	runner.SetResponse("tmux list-sessions", []byte("gt-gastown-nux 0\n"))
+
+	config := testConfig()
+	config.TownRoot = townRoot
+	config.Runner = runner
+	server := NewServer(config, beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&gastown.CommandError{Name: "tmux", Command: "tmux capture-pane", Err: errors.New("exit status 1")}, http.StatusBadGateway, "PANE_CAPTURE_FAILED"},
+		{&gastown.CommandNotFoundError{Name: "tmux"}, http.StatusServiceUnavailable, "TMUX_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		runner.SetError("tmux capture-pane", tt.err)
+
+		req := httptest.NewRequest("GET", "/api/v1/town/agents/gastown%2Fnux/pane", nil)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if w.Code != tt.status || resp.Code != tt.code {
+			t.Errorf("%T: expected %d %s, got %d %s", tt.err, tt.status, tt.code, w.Code, resp.Code)
+		}
+	}
+}
+
+func TestHandleGastownError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&gastown.TownNotFoundError{Root: "/tmp/town"}, http.StatusNotFound, "TOWN_NOT_FOUND"},
+		{&gastown.RigNotFoundError{Name: "beads"}, http.StatusNotFound, "RIG_NOT_FOUND"},
+		{&gastown.NotFoundError{Kind: "convoy", ID: "hq-cv-1"}, http.StatusNotFound, "CONVOY_NOT_FOUND"},
+		{&gastown.GTNotFoundError{}, http.StatusServiceUnavailable, "GT_NOT_FOUND"},
+		{&gastown.GTExecutionError{Command: "gt convoy list --json", Err: errors.New("exit status 1")}, http.StatusBadGateway, "GT_ERROR"},
+		{&gastown.CommandNotFoundError{Name: "tmux"}, http.StatusServiceUnavailable, "TMUX_NOT_FOUND"},
+		{&gastown.CommandError{Name: "git", Command: "git merge-base main feature", Err: errors.New("exit status 128")}, http.StatusBadGateway, "GIT_ERROR"},
+		{&gastown.ParseError{Command: "convoy list", Err: errors.New("bad json")}, http.StatusInternalServerError, "PARSE_ERROR"},
+		{errors.New("boom"), http.StatusInternalServerError, "GASTOWN_ERROR"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handleGastownError(w, fmt.Errorf("wrapped: %w", tt.err))
+
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if w.Code != tt.status || resp.Code != tt.code {
+			t.Errorf("%T: expected %d %s, got %d %s", tt.err, tt.status, tt.code, w.Code, resp.Code)
+		}
+	}
+}
+
+func TestTownNotFound(t *testing.T) {
+	config := testConfig()
+	adapter := beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor())
+
+	server := NewServer(config, adapter)
+
+	req := httptest.NewRequest("GET", "/api/v1/town/rigs/gastown", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if w.Code != http.StatusNotFound || resp.Code != "TOWN_NOT_FOUND" {
+		t.Errorf("Expected 404 TOWN_NOT_FOUND, got %d %s", w.Code, resp.Code)
+	}
+}
+
+func TestWriteDisabled(t *testing.T) {
+	config := testConfig()
+	adapter := beads.NewCLIAdapter("")
+
+	server := NewServer(config, adapter)
+
+	for _, path := range []string{"/api/v1/town/mail/gastown%2Fnux", "/api/v1/town/agents/gastown%2Fnux/nudge"} {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`{"subject": "hi", "message": "hi"}`))
+		w := httptest.NewRecorder()
+
+		server.Handler().ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected status 403, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestMailUnreadWithoutRecipient(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := gastown.NewMockRunner()
+	runner.SetResponse("gt mail inbox", []byte(`[
+		{"id": "msg-1", "from": "mayor/", "subject": "Hi", "read": false},
+		{"id": "msg-2", "from": "mayor/", "subject": "Again", "read": false},
+		{"id": "msg-3", "from": "mayor/", "subject": "Old", "read": true}
+	]`))
+
+	config := testConfig()
+	config.TownRoot = townRoot
+	config.Runner = runner
+	server := NewServer(config, beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+
+	req := httptest.NewRequest("GET", "/api/v1/town/mail/gastown%2Fnux", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp MailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Total != 3 || resp.Unread != 2 {
+		t.Errorf("Expected 3 messages with 2 unread, got total=%d unread=%d", resp.Total, resp.Unread)
+	}
+}
+
+func TestSendMailValidation(t *testing.T) {
+	config := testConfig()
+	config.WriteEnabled = true
+	config.AuditLog = filepath.Join(t.TempDir(), "audit.log")
+	adapter := beads.NewCLIAdapter("")
+
+	server := NewServer(config, adapter)
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`not json`, http.StatusBadRequest},
+		{`{"body": "no subject"}`, http.StatusBadRequest},
+		{`{"subject": "hi"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/v1/town/mail/gastown%2Fnux", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		server.Handler().ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.want, w.Code)
+		}
+	}
+}
+
+func TestSendMailSender(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := gastown.NewMockRunner()
+	runner.SetResponse("tmux list-sessions", []byte(""))
+	runner.SetResponse("gt mail", []byte("[]"))
+
+	config := testConfig()
+	config.TownRoot = townRoot
+	config.Runner = runner
+	config.WriteEnabled = true
+	config.AuditLog = filepath.Join(t.TempDir(), "audit.log")
+	server := NewServer(config, beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+
+	// A sender in the body is ignored
+	req := httptest.NewRequest("POST", "/api/v1/town/mail/mayor%2F", strings.NewReader(`{"subject": "hi", "from": "gastown/nux"}`))
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, c := range runner.Calls {
+		if strings.HasPrefix(c.String(), "gt mail send") {
+			if len(c.Env) != 1 || c.Env[0] != "GT_ROLE="+MailSender {
+				t.Errorf("Expected mail sent as %s, got env %v", MailSender, c.Env)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected gt mail send, got %+v", runner.Calls)
+}
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := NewAuditLog(path)
+	if err != nil {
+		t.Fatalf("NewAuditLog() returned error: %v", err)
+	}
+
+	audit.Record(AuditEntry{Action: "agent.nudge", Target: "gastown/nux"})
+	audit.Record(AuditEntry{Action: "mail.send", Target: "mayor/", Error: "gt failed"})
+	if err := audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(lines))
+	}
+
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Failed to parse entry: %v", err)
+	}
+	if entry.Action != "mail.send" || entry.Error != "gt failed" || entry.Time.IsZero() {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+}
+
+func TestAgentLifecycleConfirmation(t *testing.T) {
+	townRoot := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(townRoot, "mayor"),
+		filepath.Join(townRoot, "gastown", "polecats", "nux"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner := gastown.NewMockRunner()
+	runner.SetResponse("tmux list-sessions", []byte(""))
+	runner.SetError("tmux has-session", errors.New("can't find session"))
+
+	config := testConfig()
+	config.TownRoot = townRoot
+	config.Runner = runner
+	config.WriteEnabled = true
+	config.AuditLog = filepath.Join(t.TempDir(), "audit.log")
+	adapter := beads.NewCLIAdapter("")
+
+	server := NewServer(config, adapter)
+
+	req := httptest.NewRequest("POST", "/api/v1/town/agents/gastown%2Fnux/stop", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409 without confirmation, got %d", w.Code)
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if errResp.Code != "CONFIRMATION_REQUIRED" {
+		t.Errorf("Expected CONFIRMATION_REQUIRED, got %s", errResp.Code)
+	}
+
+	// The agent has no session, so a confirmed stop changes nothing
+	req = httptest.NewRequest("POST", "/api/v1/town/agents/gastown%2Fnux/stop?confirm=true", nil)
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp LifecycleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Changed || resp.Status != "offline" {
+		t.Errorf("Expected idempotent stop, got %+v", resp)
+	}
+	if runner.Called("tmux kill-session") {
+		t.Error("Expected no kill-session without a running session")
+	}
+
+	// With a running session the confirmed stop kills exactly that session
+	delete(runner.Errors, "tmux has-session")
+	runner.SetResponse("tmux has-session", nil)
+	runner.SetResponse("tmux kill-session", nil)
+
+	req = httptest.NewRequest("POST", "/api/v1/town/agents/gastown%2Fnux/stop?confirm=true", nil)
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if !resp.Changed {
+		t.Errorf("Expected stop to change the agent, got %+v", resp)
+	}
+	if !runner.Called("tmux kill-session -t =gt-gastown-nux") {
+		t.Errorf("Expected kill-session for gt-gastown-nux, got %+v", runner.Calls)
+	}
+}
+
+func TestMetricsSeries(t *testing.T) {
+	config := testConfig()
+	adapter := beads.NewCLIAdapter("")
+
+	server := NewServer(config, adapter)
+	if err := server.store.RecordSamples(time.Now().Add(-time.Minute), map[string]float64{"board.blocked": 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/metrics/series?name=board.blocked&from=1h&step=5m", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Points []struct {
+			Value float64 `json:"value"`
+		} `json:"points"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(resp.Points) != 1 || resp.Points[0].Value != 4 {
+		t.Errorf("Unexpected points: %+v", resp.Points)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/metrics/series?name=board.blocked&step=soon", nil)
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for bad step, got %d", w.Code)
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	config := testConfig()
+	config.Version = "1.2.3"
+	executor := beads.NewMockExecutor()
+	executor.SetResponse("status", []byte("ok"))
+	executor.SetResponse("--version", []byte("bd 1.0.0"))
+	executor.SetResponse("list --json", []byte(`[{"id": "bd-1", "title": "One", "status": "open"}]`))
+	adapter := beads.NewCLIAdapterWithExecutor("", executor)
+
+	server := NewServer(config, adapter)
+
+	req := httptest.NewRequest("GET", "/api/v1/health", nil)
+	server.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	// The issue gauge calls bd while scraping, so those calls show up from
+	// the second scrape on.
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		req = httptest.NewRequest("GET", "/metrics", nil)
+		w = httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected text/plain content type, got %s", ct)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`gvid_build_info{version="1.2.3"} 1`,
+		`gvid_http_requests_total{method="GET",route="GET /api/v1/health",code="200"} 1`,
+		`gvid_http_requests_total{method="GET",route="GET /metrics",code="200"} 1`,
+		`gvid_http_request_duration_seconds_count{method="GET",route="GET /api/v1/health"} 1`,
+		`gvid_bd_calls_total{subcommand="status"} 1`,
+		`gvid_bd_calls_total{subcommand="list"} 1`,
+		`gvid_issues{status="pending"} 1`,
+		`gvid_sse_clients 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# comment\n\nsecret-a admin ops\nsecret-r read\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := LoadTokens(path)
+	if err != nil {
+		t.Fatalf("LoadTokens() returned error: %v", err)
+	}
+	if tok, ok := tokens.Lookup("secret-a"); !ok || tok.Scope != ScopeAdmin || tok.Name != "ops" {
+		t.Errorf("Unexpected admin token: %+v, %v", tok, ok)
+	}
+	if tok, ok := tokens.Lookup("secret-r"); !ok || tok.Scope != ScopeRead || tok.Name != "line 4" {
+		t.Errorf("Unexpected read token: %+v, %v", tok, ok)
+	}
+	if _, ok := tokens.Lookup("secret"); ok {
+		t.Error("Expected unknown secret to be rejected")
+	}
+
+	for _, content := range []string{"", "secret-a owner\n", "secret-a\n"} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTokens(path); err == nil {
+			t.Errorf("Expected error for token file %q", content)
+		}
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	config := testConfig()
+	config.Tokens = Tokens{}
+	config.Tokens[sha256.Sum256([]byte("admin-secret"))] = Token{Name: "ops", Scope: ScopeAdmin}
+	config.Tokens[sha256.Sum256([]byte("read-secret"))] = Token{Name: "grafana", Scope: ScopeRead}
+	adapter := beads.NewCLIAdapter("")
+
+	server := NewServer(config, adapter)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+		code   string
+	}{
+		{"health is public", "GET", "/api/v1/health", "", 0, ""},
+		{"missing token", "GET", "/api/v1/town/status", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"invalid token", "GET", "/api/v1/town/status", "nope", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"read token reads", "GET", "/api/v1/town/status", "read-secret", http.StatusOK, ""},
+		{"metrics need a token", "GET", "/metrics", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"read token cannot write", "POST", "/api/v1/town/agents/gastown%2Fnux/nudge", "read-secret", http.StatusForbidden, "INSUFFICIENT_SCOPE"},
+		{"admin token can write", "POST", "/api/v1/town/agents/gastown%2Fnux/nudge", "admin-secret", http.StatusForbidden, "WRITE_DISABLED"},
+		{"query token only for streams", "GET", "/api/v1/town/status?token=read-secret", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			server.Handler().ServeHTTP(w, req)
+
+			if tt.status == 0 {
+				if w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+					t.Errorf("Expected public access, got %d", w.Code)
+				}
+				return
+			}
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.code == "" {
+				return
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if resp.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, resp.Code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/events?token=read-secret", nil)
+	if got := bearerToken(req); got != "read-secret" {
+		t.Errorf("Expected query token for event stream, got %q", got)
+	}
+}
+
+func TestReload(t *testing.T) {
+	config := testConfig()
+	adapter := beads.NewCLIAdapter("")
+	server := NewServer(config, adapter)
+
+	config.CORSOrigins = []string{"http://dash.local"}
+	config.WriteEnabled = true
+	config.Port = 9999
+	if err := server.Reload(config); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/health", nil)
+	req.Header.Set("Origin", "http://dash.local")
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://dash.local" {
+		t.Errorf("Expected reloaded CORS origin, got %q", got)
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/town/agents/gastown%2Fnux/nudge", strings.NewReader(`{"message": "hi"}`))
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if w.Code == http.StatusForbidden {
+		t.Errorf("Expected write mode to be enabled by reload, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if changed := restartOnlyChanges(server.config, config); len(changed) != 1 || changed[0] != "port" {
+		t.Errorf("Expected only port to need a restart, got %v", changed)
+	}
+}
+
+func TestReload_HealthRules(t *testing.T) {
+	server := NewServer(testConfig(), beads.NewCLIAdapter(""))
+
+	config := testConfig()
+	config.HealthRules = gastown.DefaultHealthRules()
+	rule := config.HealthRules[gastown.RolePolecat]
+	rule.StuckAfter = time.Hour
+	config.HealthRules[gastown.RolePolecat] = rule
+
+	// Reload runs on the signal goroutine while requests read the settings
+	done := make(chan error)
+	go func() { done <- server.Reload(config) }()
+	_ = server.settings().healthRules
+	if err := <-done; err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	if got := server.settings().healthRules[gastown.RolePolecat].StuckAfter; got != time.Hour {
+		t.Errorf("Expected reloaded polecat rule, got stuck_after %s", got)
+	}
+	if server.config.HealthRules != nil {
+		t.Error("Expected the startup config to be left unchanged")
+	}
+}
+
+func TestRequestIDLogging(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+	defer slog.SetDefault(prev)
+
+	// The real executor fails to find or run bd here, which is logged
+	t.Setenv("PATH", t.TempDir())
+	config := testConfig()
+	server := NewServer(config, beads.NewCLIAdapter(t.TempDir()))
+
+	req := httptest.NewRequest("GET", "/api/v1/board", nil)
+	req.Header.Set("X-Request-ID", "trace-42")
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Request-ID"); got != "trace-42" {
+		t.Errorf("Expected client request ID to be echoed, got %q", got)
+	}
+
+	var access, exec map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Log line is not JSON: %q", line)
+		}
+		switch rec["msg"] {
+		case "request":
+			access = rec
+		case "exec":
+			exec = rec
+		}
+	}
+
+	if access == nil {
+		t.Fatal("Expected an access log record")
+	}
+	if access["request_id"] != "trace-42" || access["route"] != "GET /api/v1/board" ||
+		access["status"] != float64(w.Code) || access["bytes"] != float64(w.Body.Len()) {
+		t.Errorf("Unexpected access log record %v", access)
+	}
+	if exec == nil || exec["request_id"] != "trace-42" || exec["cmd"] != "bd" || exec["exit_code"] != -1.0 {
+		t.Errorf("Expected bd call logged under the request ID, got %v", exec)
+	}
+
+	// Invalid IDs are replaced
+	req = httptest.NewRequest("GET", "/api/v1/health", nil)
+	req.Header.Set("X-Request-ID", "bad id")
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if got := w.Header().Get("X-Request-ID"); got == "" || got == "bad id" {
+		t.Errorf("Expected a generated request ID, got %q", got)
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/store"
+)
+
+// defaultHistoryWindow is the range history endpoints cover without ?from.
+const defaultHistoryWindow = 24 * time.Hour
+
+// recordAgentHistory stores status transitions seen in a new snapshot.
+func (s *Server) recordAgentHistory(snap *gastown.Snapshot) {
+	if snap.Err != nil {
+		return
+	}
+
+	for _, agent := range snap.Agents {
+		_, err := s.store.RecordStatus(store.Transition{
+			Address: agent.Address(),
+			Rig:     agent.Rig,
+			Role:    string(agent.Role),
+			Status:  string(agent.Status),
+			Reason:  agent.StatusReason,
+			At:      snap.TakenAt,
+		})
+		if err != nil {
+			slog.Warn("Failed to record agent history", "error", err)
+		}
+	}
+}
+
+// parseTimeRange reads ?from and ?to as RFC 3339 times or as durations
+// before now (e.g. from=24h or from=7d). Missing values default to the last window.
+func parseTimeRange(r *http.Request, window time.Duration) (from, to time.Time, err error) {
+	now := time.Now()
+	query := r.URL.Query()
+
+	parse := func(name string, def time.Time) (time.Time, error) {
+		v := query.Get(name)
+		if v == "" {
+			return def, nil
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		if d, err := parseAgo(v); err == nil && d >= 0 {
+			return now.Add(-d), nil
+		}
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time or a duration like 24h or 7d", name)
+	}
+
+	if to, err = parse("to", now); err != nil {
+		return
+	}
+	if from, err = parse("from", to.Add(-window)); err != nil {
+		return
+	}
+	if !from.Before(to) {
+		err = fmt.Errorf("from must be before to")
+	}
+	return
+}
+
+// parseAgo parses a Go duration, also accepting whole days such as "7d".
+func parseAgo(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(v)
+}
+
+// handleAgentHistory handles GET /api/v1/town/agents/{address}/history.
+// Query params: from, to (RFC 3339 or duration ago; default last 24h).
+func (s *Server) handleAgentHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	address := r.PathValue("address")
+
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "address required")
+		return
+	}
+
+	from, to, err := parseTimeRange(r, defaultHistoryWindow)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", err.Error())
+		return
+	}
+
+	// Agents that have since been removed keep their history
+	transitions := s.store.Transitions(address)
+	if len(transitions) == 0 {
+		if _, err := s.gtAdapter.Agent(ctx, address); err != nil {
+			handleGastownError(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, store.BuildTimeline(address, transitions, s.observed(), from, to))
+}
+
+// RigAvailability summarizes how available a rig's agents were over a range.
+type RigAvailability struct {
+	Rig          string              `json:"rig"`
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	Availability float64             `json:"availability"` // Mean over tracked agents
+	TimeInState  map[string]float64  `json:"time_in_state"`
+	Agents       []AgentAvailability `json:"agents"`
+}
+
+// AgentAvailability is one agent's share of a rig availability summary.
+type AgentAvailability struct {
+	Address        string             `json:"address"`
+	Role           string             `json:"role,omitempty"`
+	Status         string             `json:"status,omitempty"`
+	Availability   float64            `json:"availability"`
+	TrackedSeconds float64            `json:"tracked_seconds"`
+	TimeInState    map[string]float64 `json:"time_in_state"`
+	OfflineCount   int                `json:"offline_count"`
+}
+
+// handleRigAvailability handles GET /api/v1/town/rigs/{name}/availability.
+// Query params: from, to (RFC 3339 or duration ago; default last 24h).
+func (s *Server) handleRigAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := r.PathValue("name")
+
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "rig name required")
+		return
+	}
+
+	from, to, err := parseTimeRange(r, defaultHistoryWindow)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", err.Error())
+		return
+	}
+
+	if _, err := s.gtAdapter.Rig(ctx, name); err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	summary := RigAvailability{
+		Rig:         name,
+		From:        from,
+		To:          to,
+		TimeInState: make(map[string]float64),
+		Agents:      []AgentAvailability{},
+	}
+
+	observed := s.observed()
+	tracked := 0
+	for _, address := range s.store.Addresses() {
+		transitions := s.store.Transitions(address)
+		if len(transitions) == 0 || transitions[len(transitions)-1].Rig != name {
+			continue
+		}
+
+		tl := store.BuildTimeline(address, transitions, observed, from, to)
+		summary.Agents = append(summary.Agents, AgentAvailability{
+			Address:        address,
+			Role:           transitions[len(transitions)-1].Role,
+			Status:         tl.Status,
+			Availability:   tl.Availability,
+			TrackedSeconds: tl.TrackedSeconds,
+			TimeInState:    tl.TimeInState,
+			OfflineCount:   tl.OfflineCount,
+		})
+		for status, seconds := range tl.TimeInState {
+			summary.TimeInState[status] += seconds
+		}
+		if tl.TrackedSeconds > 0 {
+			summary.Availability += tl.Availability
+			tracked++
+		}
+	}
+	if tracked > 0 {
+		summary.Availability /= float64(tracked)
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultSocketMode is the permission of the Unix socket when none is
+// configured: only the daemon's user may connect.
+const DefaultSocketMode fs.FileMode = 0600
+
+// listenFDsStart is the first file descriptor passed by systemd.
+const listenFDsStart = 3
+
+// listeners opens the sockets the server accepts connections on: those
+// passed by systemd socket activation if any, otherwise the configured
+// TCP address and Unix socket. TCP listeners are wrapped with tlsCfg.
+func (s *Server) listeners(tlsCfg *tls.Config) ([]net.Listener, error) {
+	lns, err := systemdListeners()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lns) == 0 {
+		lns, err = s.configuredListeners()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, ln := range lns {
+		if tlsCfg != nil && ln.Addr().Network() != "unix" {
+			lns[i] = tls.NewListener(ln, tlsCfg)
+		}
+	}
+	return lns, nil
+}
+
+// configuredListeners listens on the TCP address unless disabled, and on
+// the Unix socket if one is set.
+func (s *Server) configuredListeners() ([]net.Listener, error) {
+	var lns []net.Listener
+	closeAll := func() {
+		for _, ln := range lns {
+			ln.Close()
+		}
+	}
+
+	if !s.config.DisableTCP {
+		addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+
+	if s.config.Socket != "" {
+		ln, err := listenUnix(s.config.Socket, s.config.SocketMode, s.config.SocketOwner)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+
+	if len(lns) == 0 {
+		return nil, fmt.Errorf("no listeners: TCP is disabled and no socket is configured")
+	}
+	return lns, nil
+}
+
+// listenUnix listens on a Unix socket at path with the given mode and
+// optional "user[:group]" owner. A stale socket left by a previous run is
+// removed first; any other file at path is an error.
+func listenUnix(path string, mode fs.FileMode, owner string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// Create the socket with no access so it is never briefly reachable
+	// with broader permissions than configured.
+	oldMask := syscall.Umask(0777)
+	ln, err := net.Listen("unix", path)
+	syscall.Umask(oldMask)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner != "" {
+		uid, gid, err := lookupOwner(owner)
+		if err != nil {
+			ln.Close()
+			return nil, err
+		}
+		if err := os.Chown(path, uid, gid); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to set socket owner: %w", err)
+		}
+	}
+
+	if mode == 0 {
+		mode = DefaultSocketMode
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket mode: %w", err)
+	}
+	return ln, nil
+}
+
+// lookupOwner resolves "user", "user:group" or ":group" to IDs; -1 leaves
+// that ID unchanged.
+func lookupOwner(owner string) (int, int, error) {
+	uid, gid := -1, -1
+	userName, groupName, _ := strings.Cut(owner, ":")
+
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unknown socket owner: %w", err)
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unknown socket group: %w", err)
+		}
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+	return uid, gid, nil
+}
+
+// systemdListeners returns the sockets passed by systemd socket
+// activation, or none when the process was not socket activated.
+func systemdListeners() ([]net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+
+	// The descriptors belong to this process only; do not pass them on to
+	// commands we run.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	lns := make([]net.Listener, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		syscall.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range lns {
+				l.Close()
+			}
+			return nil, fmt.Errorf("socket activation fd %d: %w", fd, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+type unixConnContextKey struct{}
+
+// markUnixConn records in the connection context whether the client
+// connected over a Unix socket.
+func markUnixConn(ctx context.Context, c net.Conn) context.Context {
+	if c.LocalAddr().Network() == "unix" {
+		return context.WithValue(ctx, unixConnContextKey{}, true)
+	}
+	return ctx
+}
+
+// isUnixConn reports whether the request arrived over a Unix socket,
+// where file permissions rather than tokens control access.
+func isUnixConn(ctx context.Context) bool {
+	unix, _ := ctx.Value(unixConnContextKey{}).(bool)
+	return unix
+}
+
+// serveAll serves on every listener. It returns the first error other
+// than http.ErrServerClosed, or http.ErrServerClosed once all have stopped.
+func serveAll(serve func(net.Listener) error, lns []net.Listener) error {
+	errs := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func(ln net.Listener) {
+			errs <- serve(ln)
+		}(ln)
+	}
+
+	for range lns {
+		if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return http.ErrServerClosed
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+)
+
+// shortTempDir returns a temp dir short enough for Unix socket paths.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "gvid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := shortTempDir(t)
+	path := filepath.Join(dir, "gvid.sock")
+
+	ln, err := listenUnix(path, 0660, "")
+	if err != nil {
+		t.Fatalf("listenUnix() returned error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0660 {
+		t.Errorf("Expected mode 0660, got %o", info.Mode().Perm())
+	}
+
+	// Simulate a crash that leaves the socket file behind.
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	ln, err = listenUnix(path, 0, "")
+	if err != nil {
+		t.Fatalf("Expected stale socket to be replaced, got: %v", err)
+	}
+	defer ln.Close()
+	if info, _ := os.Stat(path); info.Mode().Perm() != DefaultSocketMode {
+		t.Errorf("Expected default mode %o, got %o", DefaultSocketMode, info.Mode().Perm())
+	}
+
+	regular := filepath.Join(dir, "file")
+	writeTestFile(t, regular, []byte("x"))
+	if _, err := listenUnix(regular, 0, ""); err == nil {
+		t.Error("Expected error when a regular file is in the way")
+	}
+
+	if _, err := listenUnix(filepath.Join(dir, "other.sock"), 0, "no-such-user-gvid"); err == nil {
+		t.Error("Expected error for unknown owner")
+	}
+}
+
+func TestUnixSocketBypassesTokens(t *testing.T) {
+	config := testConfig()
+	config.Socket = filepath.Join(shortTempDir(t), "gvid.sock")
+	config.DisableTCP = true
+	config.Tokens = Tokens{}
+	server := NewServer(config, beads.NewCLIAdapter(""))
+
+	lns, err := server.listeners(nil)
+	if err != nil {
+		t.Fatalf("listeners() returned error: %v", err)
+	}
+	if len(lns) != 1 || lns[0].Addr().Network() != "unix" {
+		t.Fatalf("Expected only the Unix socket, got %v", lns)
+	}
+
+	hs := &http.Server{Handler: server.Handler(), ConnContext: markUnixConn}
+	go hs.Serve(lns[0])
+	defer hs.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", config.Socket)
+		},
+	}}
+	resp, err := client.Get("http://gvid/api/v1/town/status")
+	if err != nil {
+		t.Fatalf("Request over Unix socket failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 without a token over the socket, got %d", resp.StatusCode)
+	}
+}
+
+func TestConfiguredListenersNone(t *testing.T) {
+	server := &Server{config: Config{DisableTCP: true}}
+	if _, err := server.configuredListeners(); err == nil {
+		t.Error("Expected error with TCP disabled and no socket")
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	config := testConfig()
+	config.Socket = filepath.Join(shortTempDir(t), "gvid.sock")
+	config.DisableTCP = true
+	server := NewServer(config, beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Start()
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", config.Socket)
+		},
+	}}
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://gvid/api/v1/events"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != "event: connected\n" {
+		t.Fatalf("Expected connected event, got %q", line)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+
+	rest, _ := io.ReadAll(reader)
+	if !strings.Contains(string(rest), "event: shutdown\n") {
+		t.Errorf("Expected a final shutdown event, got %q", rest)
+	}
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Expected Start to return ErrServerClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after Shutdown")
+	}
+
+	if _, err := os.Stat(config.Socket); !os.IsNotExist(err) {
+		t.Errorf("Expected socket to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/store"
+)
+
+// defaultSeriesWindow is the range /metrics/series covers without ?from.
+const defaultSeriesWindow = 24 * time.Hour
+
+// SeriesNamesResponse is the response for GET /api/v1/metrics/series
+// without ?name.
+type SeriesNamesResponse struct {
+	Names []string `json:"names"`
+	Total int      `json:"total"`
+}
+
+// SeriesResponse is the response for GET /api/v1/metrics/series?name=...
+type SeriesResponse struct {
+	Name   string        `json:"name"`
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Step   string        `json:"step"`
+	Points []store.Point `json:"points"`
+	Total  int           `json:"total"`
+}
+
+// handleMetricsSeries handles GET /api/v1/metrics/series.
+// Query params: name (omit to list names), from, to (RFC 3339 or duration
+// ago; default last 24h), step (bucket width, e.g. 5m; default raw points).
+func (s *Server) handleMetricsSeries(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		names := s.store.SeriesNames()
+		writeJSON(w, http.StatusOK, SeriesNamesResponse{
+			Names: names,
+			Total: len(names),
+		})
+		return
+	}
+
+	from, to, err := parseTimeRange(r, defaultSeriesWindow)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", err.Error())
+		return
+	}
+
+	var step time.Duration
+	if v := r.URL.Query().Get("step"); v != "" {
+		step, err = parseAgo(v)
+		if err != nil || step <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAM", "step must be a positive duration like 5m")
+			return
+		}
+	}
+
+	points := s.store.Series(name, from, to, step)
+	writeJSON(w, http.StatusOK, SeriesResponse{
+		Name:   name,
+		From:   from,
+		To:     to,
+		Step:   step.String(),
+		Points: points,
+		Total:  len(points),
+	})
+}
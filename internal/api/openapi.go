@@ -0,0 +1,275 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// openAPIVersion is the OpenAPI specification version of the document.
+const openAPIVersion = "3.0.3"
+
+// pathParamPattern matches {name} segments in route patterns.
+var pathParamPattern = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// handleOpenAPI handles GET /api/v1/openapi.json.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPI())
+}
+
+// openAPI builds the OpenAPI document for the route table.
+func (s *Server) openAPI() map[string]interface{} {
+	gen := newSchemaGen()
+	errorRef := gen.schema(reflect.TypeOf(ErrorResponse{}))
+
+	paths := make(map[string]interface{})
+	for _, rt := range s.routes() {
+		method, routePath, _ := strings.Cut(rt.pattern, " ")
+
+		item, ok := paths[routePath].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[routePath] = item
+		}
+		item[strings.ToLower(method)] = gen.operation(rt, method, routePath, errorRef)
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "Gastown Viewer Intent API",
+			"description": "Read-only views of Beads issues and Gas Town, plus audited write actions when gvid runs with --write-enabled.",
+			"version":     s.config.Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Required when gvid runs with --token-file. GET needs a read or admin token; other methods need admin.",
+				},
+			},
+		},
+	}
+}
+
+// operation describes one route.
+func (g *schemaGen) operation(rt route, method, routePath string, errorRef map[string]interface{}) map[string]interface{} {
+	var params []interface{}
+	for _, m := range pathParamPattern.FindAllStringSubmatch(routePath, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, p := range rt.query {
+		params = append(params, map[string]interface{}{
+			"name":        p.name,
+			"in":          "query",
+			"description": p.description,
+			"schema":      map[string]interface{}{"type": p.typ},
+		})
+	}
+
+	content := make(map[string]interface{})
+	if rt.response != nil {
+		content["application/json"] = map[string]interface{}{"schema": g.responseSchema(rt.response)}
+	}
+	for _, media := range rt.media {
+		content[media] = map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+	}
+
+	ok := map[string]interface{}{"description": "OK"}
+	if len(content) > 0 {
+		ok["content"] = content
+	}
+	responses := map[string]interface{}{
+		"200": ok,
+		"default": map[string]interface{}{
+			"description": "Error",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": errorRef},
+			},
+		},
+	}
+	if rt.conditional {
+		responses["304"] = map[string]interface{}{"description": "Not modified since the ETag or time given"}
+	}
+
+	op := map[string]interface{}{
+		"operationId": operationID(method, routePath),
+		"summary":     rt.summary,
+		"tags":        []string{rt.tag},
+		"responses":   responses,
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if rt.body != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": g.schema(reflect.TypeOf(rt.body))},
+			},
+		}
+	}
+	if requiresAuth(&http.Request{URL: &url.URL{Path: routePath}}) {
+		op["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+	}
+	return op
+}
+
+// responseSchema describes a response value or a oneOf of them.
+func (g *schemaGen) responseSchema(v interface{}) map[string]interface{} {
+	alternatives, ok := v.(oneOf)
+	if !ok {
+		return g.schema(reflect.TypeOf(v))
+	}
+	var schemas []interface{}
+	for _, alt := range alternatives {
+		schemas = append(schemas, g.schema(reflect.TypeOf(alt)))
+	}
+	return map[string]interface{}{"oneOf": schemas}
+}
+
+// operationID derives an ID such as getTownRigsNameMergeQueue from a route.
+func operationID(method, routePath string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	routePath = strings.TrimPrefix(routePath, "/api/v1")
+	for _, word := range strings.FieldsFunc(routePath, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// schemaGen turns Go types into JSON Schema, collecting named struct
+// types under components/schemas.
+type schemaGen struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+func newSchemaGen() *schemaGen {
+	return &schemaGen{
+		schemas: make(map[string]interface{}),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema describes t as encoding/json would encode it.
+func (g *schemaGen) schema(t reflect.Type) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.componentName(t)
+			g.names[t] = name
+			g.schemas[name] = nil // reserve the name for recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		// interface{} and anything else encode as arbitrary JSON
+		return map[string]interface{}{}
+	}
+}
+
+// componentName returns t's name, prefixed with its package when another
+// type already uses the plain name.
+func (g *schemaGen) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	pkg := path.Base(t.PkgPath())
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
+
+// object describes a struct's JSON fields. Fields without omitempty are
+// listed as required.
+func (g *schemaGen) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	g.fields(t, properties, &required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields adds t's JSON fields to properties, flattening embedded structs.
+func (g *schemaGen) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, properties, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		properties[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+)
+
+func fetchOpenAPI(t *testing.T, server *Server) map[string]interface{} {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("OpenAPI document is not JSON: %v", err)
+	}
+	return doc
+}
+
+// TestOpenAPIDescribesEveryRoute checks that every route the server
+// registers appears in the document with a summary, a 200 response and
+// its path parameters.
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	config := testConfig()
+	config.Version = "1.2.3"
+	server := NewServer(config, beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+
+	doc := fetchOpenAPI(t, server)
+	if doc["openapi"] != openAPIVersion {
+		t.Errorf("Expected openapi %s, got %v", openAPIVersion, doc["openapi"])
+	}
+	if info := doc["info"].(map[string]interface{}); info["version"] != "1.2.3" {
+		t.Errorf("Expected info.version 1.2.3, got %v", info["version"])
+	}
+
+	paths := doc["paths"].(map[string]interface{})
+	operations := 0
+	for _, item := range paths {
+		operations += len(item.(map[string]interface{}))
+	}
+	routes := server.routes()
+	if operations != len(routes) {
+		t.Errorf("Expected %d operations, got %d", len(routes), operations)
+	}
+
+	for _, rt := range routes {
+		method, routePath, _ := strings.Cut(rt.pattern, " ")
+
+		// The mux must route a concrete request to this pattern
+		concrete := pathParamPattern.ReplaceAllString(routePath, "x")
+		if _, pattern := server.mux.Handler(httptest.NewRequest(method, concrete, nil)); pattern != rt.pattern {
+			t.Errorf("%s: request for %s matched %q", rt.pattern, concrete, pattern)
+		}
+
+		item, ok := paths[routePath].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: path missing from document", rt.pattern)
+			continue
+		}
+		op, ok := item[strings.ToLower(method)].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: method missing from document", rt.pattern)
+			continue
+		}
+		if op["summary"] == "" || op["summary"] == nil {
+			t.Errorf("%s: missing summary", rt.pattern)
+		}
+		ok200, _ := op["responses"].(map[string]interface{})["200"].(map[string]interface{})
+		if _, hasContent := ok200["content"]; !hasContent {
+			t.Errorf("%s: 200 response has no content", rt.pattern)
+		}
+
+		want := len(pathParamPattern.FindAllString(routePath, -1))
+		got := 0
+		params, _ := op["parameters"].([]interface{})
+		for _, p := range params {
+			if p.(map[string]interface{})["in"] == "path" {
+				got++
+			}
+		}
+		if got != want {
+			t.Errorf("%s: expected %d path parameters, got %d", rt.pattern, want, got)
+		}
+	}
+
+	// Every schema reference resolves
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := schemas[name]; !ok {
+					t.Errorf("Unresolved schema reference %s", ref)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+}
+
+// TestRoutesRegisteredFromTable guards against handlers registered outside
+// the route table, which the OpenAPI document would not describe.
+func TestRoutesRegisteredFromTable(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || file == "routes.go" || file == "static.go" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "mux.Handle") {
+			t.Errorf("%s registers a handler outside the route table in routes.go", file)
+		}
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	server := NewServer(testConfig(), beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+	doc := fetchOpenAPI(t, server)
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	agents, ok := schemas["AgentsResponse"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected AgentsResponse schema")
+	}
+	props := agents["properties"].(map[string]interface{})
+	items := props["agents"].(map[string]interface{})["items"].(map[string]interface{})
+	if items["$ref"] != "#/components/schemas/Agent" {
+		t.Errorf("Expected agents to reference Agent, got %v", items)
+	}
+
+	errResp := schemas["ErrorResponse"].(map[string]interface{})
+	required := errResp["required"].([]interface{})
+	if len(required) != 2 || required[0] != "error" || required[1] != "code" {
+		t.Errorf("Expected error and code required, got %v", required)
+	}
+
+	health := doc["paths"].(map[string]interface{})["/api/v1/health"].(map[string]interface{})["get"].(map[string]interface{})
+	if _, ok := health["security"]; ok {
+		t.Error("Expected health check to need no token")
+	}
+	board := doc["paths"].(map[string]interface{})["/api/v1/board"].(map[string]interface{})["get"].(map[string]interface{})
+	if _, ok := board["security"]; !ok {
+		t.Error("Expected board to accept a bearer token")
+	}
+	if _, ok := board["responses"].(map[string]interface{})["304"]; !ok {
+		t.Error("Expected board to document 304")
+	}
+}
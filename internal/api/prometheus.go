@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/metrics"
+)
+
+// serverMetrics holds the instruments updated while serving requests.
+// Board and town gauges are read at scrape time instead.
+type serverMetrics struct {
+	registry     *metrics.Registry
+	httpRequests *metrics.CounterVec
+	httpDuration *metrics.HistogramVec
+	bdCalls      *metrics.CounterVec
+	bdFailures   *metrics.CounterVec
+	bdDuration   *metrics.HistogramVec
+	sseEvictions *metrics.CounterVec
+}
+
+// newServerMetrics registers the daemon's metrics on reg.
+func (s *Server) newServerMetrics(reg *metrics.Registry) *serverMetrics {
+	m := &serverMetrics{
+		registry: reg,
+		httpRequests: reg.NewCounterVec("gvid_http_requests_total",
+			"HTTP requests served, by method, route and status code.", "method", "route", "code"),
+		httpDuration: reg.NewHistogramVec("gvid_http_request_duration_seconds",
+			"HTTP request latency, by method and route.", nil, "method", "route"),
+		bdCalls: reg.NewCounterVec("gvid_bd_calls_total",
+			"bd CLI invocations, by subcommand.", "subcommand"),
+		bdFailures: reg.NewCounterVec("gvid_bd_call_failures_total",
+			"bd CLI invocations that returned an error, by subcommand.", "subcommand"),
+		bdDuration: reg.NewHistogramVec("gvid_bd_call_duration_seconds",
+			"bd CLI call latency, by subcommand.", nil, "subcommand"),
+		sseEvictions: reg.NewCounterVec("gvid_sse_evictions_total",
+			"SSE clients disconnected for falling behind."),
+	}
+
+	reg.NewGaugeFunc("gvid_build_info", "Build information; the value is always 1.",
+		func(context.Context) []metrics.Sample {
+			return []metrics.Sample{{Labels: []string{s.config.Version}, Value: 1}}
+		}, "version")
+	reg.NewGaugeFunc("gvid_sse_clients", "Connected SSE event stream clients.",
+		func(context.Context) []metrics.Sample {
+			return []metrics.Sample{{Value: float64(s.sse.ClientCount())}}
+		})
+	reg.NewGaugeFunc("gvid_issues", "Beads issues per board status.",
+		s.collectIssues, "status")
+	reg.NewGaugeFunc("gvid_town_agents", "Gas Town agents per rig and status.",
+		s.collectAgents, "rig", "status")
+	reg.NewGaugeFunc("gvid_convoy_progress_percent", "Completion of each convoy.",
+		s.collectConvoys, "convoy")
+
+	return m
+}
+
+// routeOf returns the mux pattern r matched. Requests that matched no
+// route are grouped under "unmatched" to keep label cardinality bounded.
+func routeOf(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+	return r.Pattern
+}
+
+// observeRequest records one served request.
+func (m *serverMetrics) observeRequest(r *http.Request, status int, elapsed time.Duration) {
+	route := routeOf(r)
+	m.httpRequests.Inc(r.Method, route, strconv.Itoa(status))
+	m.httpDuration.Observe(elapsed.Seconds(), r.Method, route)
+}
+
+// observeBD records one bd CLI call.
+func (m *serverMetrics) observeBD(subcommand string, elapsed time.Duration, err error) {
+	m.bdCalls.Inc(subcommand)
+	if err != nil {
+		m.bdFailures.Inc(subcommand)
+	}
+	m.bdDuration.Observe(elapsed.Seconds(), subcommand)
+}
+
+// collectIssues counts issues per board column. Nothing is reported when
+// beads is unavailable.
+func (s *Server) collectIssues(ctx context.Context) []metrics.Sample {
+	board, err := s.adapter.Board(ctx)
+	if err != nil {
+		return nil
+	}
+	samples := make([]metrics.Sample, 0, len(board.Columns))
+	for _, col := range board.Columns {
+		samples = append(samples, metrics.Sample{Labels: []string{string(col.Status)}, Value: float64(col.Count)})
+	}
+	return samples
+}
+
+// collectAgents counts agents per rig and status from the town snapshot.
+// Town-level agents are reported under rig "town".
+func (s *Server) collectAgents(ctx context.Context) []metrics.Sample {
+	snap, err := s.gtCache.Snapshot(ctx)
+	if err != nil || snap.Err != nil {
+		return nil
+	}
+	type key struct {
+		rig    string
+		status gastown.AgentStatus
+	}
+	counts := make(map[key]int)
+	for _, agent := range snap.Agents {
+		rig := agent.Rig
+		if rig == "" {
+			rig = "town"
+		}
+		counts[key{rig, agent.Status}]++
+	}
+	samples := make([]metrics.Sample, 0, len(counts))
+	for k, n := range counts {
+		samples = append(samples, metrics.Sample{Labels: []string{k.rig, string(k.status)}, Value: float64(n)})
+	}
+	return samples
+}
+
+// collectConvoys reports the progress of every convoy in the snapshot.
+func (s *Server) collectConvoys(ctx context.Context) []metrics.Sample {
+	snap, err := s.gtCache.Snapshot(ctx)
+	if err != nil || snap.Err != nil || snap.Town == nil {
+		return nil
+	}
+	samples := make([]metrics.Sample, 0, len(snap.Town.Convoys))
+	for _, convoy := range snap.Town.Convoys {
+		samples = append(samples, metrics.Sample{Labels: []string{convoy.ID}, Value: float64(convoy.Progress)})
+	}
+	return samples
+}
+
+// handlePrometheus handles GET /metrics in the Prometheus text format.
+func (s *Server) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := s.metrics.registry.WriteText(r.Context(), &buf); err != nil {
+		writeError(w, http.StatusInternalServerError, "METRICS_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
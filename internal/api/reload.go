@@ -0,0 +1,91 @@
+package api
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+)
+
+// liveConfig holds the settings that can change while the server runs.
+type liveConfig struct {
+	corsOrigins  []string
+	writeEnabled bool
+	tokens       Tokens
+	healthRules  gastown.HealthRules // As configured; nil means the defaults
+}
+
+func newLiveConfig(config Config) *liveConfig {
+	return &liveConfig{
+		corsOrigins:  config.CORSOrigins,
+		writeEnabled: config.WriteEnabled,
+		tokens:       config.Tokens,
+		healthRules:  config.HealthRules,
+	}
+}
+
+// settings returns the current live settings.
+func (s *Server) settings() *liveConfig {
+	return s.live.Load()
+}
+
+// Reload applies a new configuration without dropping connections. CORS
+// origins, write mode, tokens and health rules take effect immediately and
+// the TLS certificate is re-read. Settings that need a restart, such as
+// listen addresses, are reported and otherwise ignored.
+func (s *Server) Reload(config Config) error {
+	old := s.live.Swap(newLiveConfig(config))
+
+	if !healthRulesEqual(old.healthRules, config.HealthRules) {
+		rules := config.HealthRules
+		if rules == nil {
+			rules = gastown.DefaultHealthRules()
+		}
+		s.gtCache.SetHealthRules(rules)
+	}
+
+	// s.config is never written after NewServer, so restart-only settings
+	// keep comparing against startup
+	if changed := restartOnlyChanges(s.config, config); len(changed) > 0 {
+		slog.Warn("Some changes take effect after a restart", "settings", strings.Join(changed, ", "))
+	}
+
+	return s.ReloadCertificates()
+}
+
+// restartOnlyChanges names the settings that differ between old and new
+// but are only read at startup.
+func restartOnlyChanges(old, new Config) []string {
+	var changed []string
+	check := func(name string, differs bool) {
+		if differs {
+			changed = append(changed, name)
+		}
+	}
+	check("host", old.Host != new.Host)
+	check("port", old.Port != new.Port)
+	check("socket", old.Socket != new.Socket || old.SocketMode != new.SocketMode ||
+		old.SocketOwner != new.SocketOwner || old.DisableTCP != new.DisableTCP)
+	check("tls", old.TLSCert != new.TLSCert || old.TLSKey != new.TLSKey || old.ClientCA != new.ClientCA)
+	check("town", old.TownRoot != new.TownRoot)
+	check("town refresh", old.TownRefresh != new.TownRefresh)
+	check("audit log", old.AuditLog != new.AuditLog)
+	check("data dir", old.DataDir != new.DataDir)
+	check("sample interval", old.SampleInterval != new.SampleInterval)
+	check("retention", old.Retention != new.Retention)
+	check("SSE heartbeat", old.SSEHeartbeat != new.SSEHeartbeat)
+	return changed
+}
+
+// healthRulesEqual reports whether a and b classify every role the same.
+func healthRulesEqual(a, b gastown.HealthRules) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for role, rule := range a {
+		if other, ok := b[role]; !ok || other != rule {
+			return false
+		}
+	}
+	return true
+}
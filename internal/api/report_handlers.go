@@ -0,0 +1,257 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/report"
+)
+
+// defaultCFDWindow is the range the cumulative flow report covers without ?from.
+const defaultCFDWindow = 30 * 24 * time.Hour
+
+// reportOptions are the query parameters shared by report endpoints.
+type reportOptions struct {
+	from, to time.Time
+	step     time.Duration
+	svg      bool
+}
+
+// parseReportOptions reads from, to, step and format. A missing from is
+// left zero for the caller to default.
+func parseReportOptions(w http.ResponseWriter, r *http.Request) (reportOptions, bool) {
+	var opts reportOptions
+	query := r.URL.Query()
+
+	switch query.Get("format") {
+	case "", "json":
+	case "svg":
+		opts.svg = true
+	default:
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", "format must be json or svg")
+		return opts, false
+	}
+
+	if query.Get("from") != "" || query.Get("to") != "" {
+		from, to, err := parseTimeRange(r, defaultCFDWindow)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAM", err.Error())
+			return opts, false
+		}
+		if query.Get("from") != "" {
+			opts.from = from
+		}
+		opts.to = to
+	}
+	if opts.to.IsZero() {
+		opts.to = time.Now()
+	}
+
+	if v := query.Get("step"); v != "" {
+		step, err := parseAgo(v)
+		if err != nil || step <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAM", "step must be a positive duration like 1d")
+			return opts, false
+		}
+		opts.step = step
+	}
+
+	return opts, true
+}
+
+// writeSVG writes an SVG image response.
+func writeSVG(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// writeBurndown fills in the default range and writes a burndown report.
+func writeBurndown(w http.ResponseWriter, opts reportOptions, scope, id, title string, issues []model.Issue) {
+	from := opts.from
+	if from.IsZero() {
+		from = report.EarliestCreated(issues)
+	}
+	if from.IsZero() || !from.Before(opts.to) {
+		from = opts.to.Add(-24 * time.Hour)
+	}
+
+	bd := report.ComputeBurndown(scope, id, title, issues, from, opts.to, opts.step)
+	if opts.svg {
+		writeSVG(w, report.BurndownSVG(bd))
+		return
+	}
+	writeJSON(w, http.StatusOK, bd)
+}
+
+// handleEpicBurndown handles GET /api/v1/reports/burndown/epic/{id}.
+// The epic and its direct children make up the scope.
+// Query params: from, to, step, format (json|svg).
+func (s *Server) handleEpicBurndown(w http.ResponseWriter, r *http.Request) {
+	if !s.checkBeadsInitialized(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	opts, ok := parseReportOptions(w, r)
+	if !ok {
+		return
+	}
+
+	epic, err := s.adapter.GetIssue(ctx, id)
+	if err != nil {
+		handleAdapterError(w, err)
+		return
+	}
+
+	all, err := s.adapter.ListIssues(ctx, model.IssueFilter{})
+	if err != nil {
+		handleAdapterError(w, err)
+		return
+	}
+	byID := make(map[string]model.Issue, len(all))
+	for _, issue := range all {
+		byID[issue.ID] = issue
+	}
+
+	issues := []model.Issue{*epic}
+	for _, child := range epic.Children {
+		if issue, ok := byID[child.ID]; ok {
+			issues = append(issues, issue)
+			continue
+		}
+		issue, err := s.adapter.GetIssue(ctx, child.ID)
+		if err != nil {
+			handleAdapterError(w, err)
+			return
+		}
+		issues = append(issues, *issue)
+	}
+
+	writeBurndown(w, opts, "epic", epic.ID, epic.Title, issues)
+}
+
+// handleConvoyBurndown handles GET /api/v1/reports/burndown/convoy/{id}.
+// Query params: from, to, step, format (json|svg).
+func (s *Server) handleConvoyBurndown(w http.ResponseWriter, r *http.Request) {
+	if !s.checkBeadsInitialized(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	opts, ok := parseReportOptions(w, r)
+	if !ok {
+		return
+	}
+
+	convoy, err := s.gtAdapter.Convoy(ctx, id)
+	if err != nil {
+		handleGastownError(w, err)
+		return
+	}
+
+	all, err := s.adapter.ListIssues(ctx, model.IssueFilter{})
+	if err != nil {
+		handleAdapterError(w, err)
+		return
+	}
+	byID := make(map[string]model.Issue, len(all))
+	for _, issue := range all {
+		byID[issue.ID] = issue
+	}
+
+	// Convoys can track issues from other rigs' beads; those are skipped
+	issues := []model.Issue{}
+	for _, issueID := range convoy.Issues {
+		if issue, ok := byID[issueID]; ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	writeBurndown(w, opts, "convoy", convoy.ID, convoy.Title, issues)
+}
+
+// handleCumulativeFlow handles GET /api/v1/reports/cfd.
+// Query params: from, to (default last 30 days), step, format (json|svg).
+func (s *Server) handleCumulativeFlow(w http.ResponseWriter, r *http.Request) {
+	if !s.checkBeadsInitialized(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+
+	opts, ok := parseReportOptions(w, r)
+	if !ok {
+		return
+	}
+	if opts.from.IsZero() {
+		opts.from = opts.to.Add(-defaultCFDWindow)
+	}
+	step := report.Step(opts.from, opts.to, opts.step)
+
+	issues, err := s.adapter.ListIssues(ctx, model.IssueFilter{})
+	if err != nil {
+		handleAdapterError(w, err)
+		return
+	}
+
+	// Board samples bucketed on the same grid as the report
+	buckets := make(map[model.Status]map[int64]float64)
+	for _, status := range report.Columns() {
+		buckets[status] = make(map[int64]float64)
+		for _, p := range s.store.Series("board."+string(status), opts.from, opts.to, step) {
+			buckets[status][p.At.UnixNano()] = p.Value
+		}
+	}
+	samples := func(status model.Status, at time.Time, step time.Duration) (float64, bool) {
+		bucket := opts.from.Add(at.Sub(opts.from) / step * step)
+		v, ok := buckets[status][bucket.UnixNano()]
+		return v, ok
+	}
+
+	cfd := report.ComputeCumulativeFlow(issues, samples, opts.from, opts.to, step)
+	if opts.svg {
+		writeSVG(w, report.CumulativeFlowSVG(cfd))
+		return
+	}
+	writeJSON(w, http.StatusOK, cfd)
+}
+
+// defaultFlowWindow is the range the flow report covers without ?from.
+const defaultFlowWindow = 90 * 24 * time.Hour
+
+// handleFlow handles GET /api/v1/reports/flow.
+// Query params: from, to (RFC 3339 or duration ago; default last 90 days).
+func (s *Server) handleFlow(w http.ResponseWriter, r *http.Request) {
+	if !s.checkBeadsInitialized(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+
+	from, to, err := parseTimeRange(r, defaultFlowWindow)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM", err.Error())
+		return
+	}
+
+	issues, err := s.adapter.ListIssues(ctx, model.IssueFilter{})
+	if err != nil {
+		handleAdapterError(w, err)
+		return
+	}
+
+	history, err := s.adapter.StatusHistory(ctx)
+	if err != nil {
+		handleAdapterError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report.ComputeFlow(issues, beads.StartedTimes(history), from, to))
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/report"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/store"
+)
+
+// route is one API endpoint. The table in routes both registers the
+// handlers and generates the OpenAPI document, so the two cannot drift.
+type route struct {
+	pattern string // ServeMux pattern, e.g. "GET /api/v1/issues/{id}"
+	handler http.HandlerFunc
+	tag     string
+	summary string
+	query   []param
+
+	// body is a value of the JSON request body type, if any.
+	body interface{}
+
+	// response is a value of the JSON 200 response type, or a oneOf of
+	// them. Nil means the response is not JSON; see media.
+	response interface{}
+
+	// media lists content types the 200 response has besides (or instead
+	// of) application/json.
+	media []string
+
+	// conditional answers If-None-Match and If-Modified-Since with 304;
+	// see Server.conditional.
+	conditional bool
+}
+
+// param is a query parameter.
+type param struct {
+	name        string
+	typ         string // OpenAPI type: string, integer or boolean
+	description string
+}
+
+// oneOf describes a response that has one of several shapes.
+type oneOf []interface{}
+
+// Query parameters shared by several routes.
+var (
+	fromParam = param{"from", "string", "Start of the range: RFC 3339 time or duration ago, e.g. 24h or 7d"}
+	toParam   = param{"to", "string", "End of the range: RFC 3339 time or duration ago (default: now)"}
+	stepParam = param{"step", "string", "Bucket width, e.g. 1h or 1d"}
+
+	reportParams = []param{fromParam, toParam, stepParam,
+		{"format", "string", "json (default) or svg"}}
+	linesParam    = param{"lines", "integer", "Number of lines to capture (default 50)"}
+	ansiParam     = param{"ansi", "boolean", "Keep ANSI escape sequences"}
+	confirmParams = []param{
+		{"confirm", "boolean", "Required for stop and restart, which interrupt running work"},
+	}
+)
+
+// routes returns every API endpoint in registration order.
+func (s *Server) routes() []route {
+	return []route{
+		{pattern: "GET /api/v1/health", handler: s.handleHealth, tag: "System",
+			summary: "Daemon and beads health", response: HealthResponse{}},
+		{pattern: "GET /api/v1/openapi.json", handler: s.handleOpenAPI, tag: "System",
+			summary: "This OpenAPI document", response: map[string]interface{}{}},
+
+		// Beads
+		{pattern: "GET /api/v1/issues", handler: s.handleListIssues, tag: "Issues",
+			summary: "List issues", response: model.IssueListResponse{}, conditional: true,
+			query: []param{
+				{"status", "string", "Filter by status"},
+				{"parent", "string", "Filter by parent issue ID"},
+				{"limit", "integer", "Maximum number of issues (default 100)"},
+				{"offset", "integer", "Number of issues to skip"},
+			}},
+		{pattern: "GET /api/v1/issues/{id}", handler: s.handleGetIssue, tag: "Issues",
+			summary: "Get an issue with its dependencies", response: model.Issue{}, conditional: true},
+		{pattern: "GET /api/v1/board", handler: s.handleBoard, tag: "Issues",
+			summary: "Issues grouped into board columns", response: model.Board{}, conditional: true},
+		{pattern: "GET /api/v1/graph", handler: s.handleGraph, tag: "Issues",
+			summary: "Issue dependency graph", response: model.Graph{}, conditional: true,
+			media: []string{"text/vnd.graphviz"},
+			query: []param{{"format", "string", "json (default) or dot"}}},
+
+		// Reports
+		{pattern: "GET /api/v1/reports/burndown/epic/{id}", handler: s.handleEpicBurndown, tag: "Reports",
+			summary: "Burndown of an epic and its children", response: report.Burndown{},
+			media: []string{"image/svg+xml"}, query: reportParams},
+		{pattern: "GET /api/v1/reports/burndown/convoy/{id}", handler: s.handleConvoyBurndown, tag: "Reports",
+			summary: "Burndown of a convoy's tracked issues", response: report.Burndown{},
+			media: []string{"image/svg+xml"}, query: reportParams},
+		{pattern: "GET /api/v1/reports/cfd", handler: s.handleCumulativeFlow, tag: "Reports",
+			summary: "Cumulative flow of issues by status", response: report.CumulativeFlow{},
+			media: []string{"image/svg+xml"}, query: reportParams},
+		{pattern: "GET /api/v1/reports/flow", handler: s.handleFlow, tag: "Reports",
+			summary: "Lead time, cycle time and throughput", response: report.Flow{},
+			query: []param{fromParam, toParam}},
+
+		// Metrics
+		{pattern: "GET /api/v1/metrics/series", handler: s.handleMetricsSeries, tag: "Metrics",
+			summary:  "Sampled metric series, or their names without ?name",
+			response: oneOf{SeriesResponse{}, SeriesNamesResponse{}},
+			query:    []param{{"name", "string", "Series name"}, fromParam, toParam, stepParam}},
+		{pattern: "GET /metrics", handler: s.handlePrometheus, tag: "Metrics",
+			summary: "Prometheus metrics", media: []string{"text/plain"}},
+
+		// Events
+		{pattern: "GET /api/v1/events", handler: s.handleEvents, tag: "Events",
+			summary: "Server-sent events for issue and town changes", media: []string{"text/event-stream"}},
+
+		// Gas Town
+		{pattern: "GET /api/v1/town", handler: s.handleTown, tag: "Town",
+			summary: "Town overview", response: gastown.Town{}, conditional: true},
+		{pattern: "GET /api/v1/town/status", handler: s.handleTownStatus, tag: "Town",
+			summary: "Town health summary", response: gastown.TownStatus{}, conditional: true},
+		{pattern: "GET /api/v1/town/rigs", handler: s.handleRigs, tag: "Rigs",
+			summary: "List rigs", response: RigsResponse{}, conditional: true},
+		{pattern: "GET /api/v1/town/rigs/{name}", handler: s.handleRig, tag: "Rigs",
+			summary: "Get a rig", response: gastown.Rig{}, conditional: true},
+		{pattern: "GET /api/v1/town/rigs/{name}/merge-queue", handler: s.handleMergeQueue, tag: "Rigs",
+			summary: "A rig's refinery merge queue", response: gastown.MergeQueue{}},
+		{pattern: "GET /api/v1/town/rigs/{name}/availability", handler: s.handleRigAvailability, tag: "Rigs",
+			summary: "Availability of a rig's agents", response: RigAvailability{},
+			query: []param{fromParam, toParam}},
+
+		{pattern: "GET /api/v1/town/agents", handler: s.handleAgents, tag: "Agents",
+			summary: "List agents", response: AgentsResponse{}, conditional: true},
+		{pattern: "GET /api/v1/town/agents/{address}/pane", handler: s.handleAgentPane, tag: "Agents",
+			summary: "Capture an agent's tmux pane", response: gastown.Pane{}, query: []param{linesParam, ansiParam}},
+		{pattern: "GET /api/v1/town/agents/{address}/pane/stream", handler: s.handleAgentPaneStream, tag: "Agents",
+			summary: "Stream an agent's tmux pane as it changes", media: []string{"text/event-stream"},
+			query: []param{linesParam, ansiParam,
+				{"interval", "string", "Polling interval, at least 500ms (default 2s)"}}},
+		{pattern: "GET /api/v1/town/agents/{address}/history", handler: s.handleAgentHistory, tag: "Agents",
+			summary: "An agent's status timeline", response: store.Timeline{},
+			query: []param{fromParam, toParam}},
+		{pattern: "POST /api/v1/town/agents/{address}/nudge", handler: s.handleNudge, tag: "Agents",
+			summary: "Send a message to an agent's session", body: NudgeRequest{}, response: NudgeResponse{}},
+		{pattern: "POST /api/v1/town/agents/{address}/start", handler: s.handleAgentStart, tag: "Agents",
+			summary: "Start an agent's session", response: LifecycleResponse{}},
+		{pattern: "POST /api/v1/town/agents/{address}/stop", handler: s.handleAgentStop, tag: "Agents",
+			summary: "Stop an agent's session", response: LifecycleResponse{}, query: confirmParams},
+		{pattern: "POST /api/v1/town/agents/{address}/restart", handler: s.handleAgentRestart, tag: "Agents",
+			summary: "Restart an agent's session", response: LifecycleResponse{}, query: confirmParams},
+
+		{pattern: "GET /api/v1/town/convoys", handler: s.handleConvoys, tag: "Convoys",
+			summary: "List convoys", response: ConvoysResponse{}, conditional: true},
+		{pattern: "GET /api/v1/town/convoys/{id}", handler: s.handleConvoy, tag: "Convoys",
+			summary: "Get a convoy", response: gastown.Convoy{}, conditional: true},
+
+		{pattern: "GET /api/v1/town/molecules", handler: s.handleMolecules, tag: "Molecules",
+			summary: "List molecules", response: MoleculesResponse{}, conditional: true},
+		{pattern: "GET /api/v1/town/molecules/{id}", handler: s.handleMolecule, tag: "Molecules",
+			summary: "Get a molecule with step timings", response: gastown.Molecule{}, conditional: true},
+		{pattern: "GET /api/v1/town/formulas/{formula}/stats", handler: s.handleFormulaStats, tag: "Molecules",
+			summary: "Run statistics for a formula", response: gastown.FormulaStats{}},
+
+		{pattern: "GET /api/v1/town/mail", handler: s.handleTownMail, tag: "Mail",
+			summary: "Mail across the town", response: MailResponse{},
+			query: []param{
+				{"type", "string", "Filter by message type"},
+				{"priority", "string", "Filter by priority"},
+				{"unread", "boolean", "Only unread messages"},
+				{"limit", "integer", "Maximum number of messages (default 50)"},
+			}},
+		{pattern: "GET /api/v1/town/mail/{address}", handler: s.handleMail, tag: "Mail",
+			summary: "An agent's mailbox", response: MailResponse{}},
+		{pattern: "POST /api/v1/town/mail/{address}", handler: s.handleSendMail, tag: "Mail",
+			summary: "Send mail to an agent", body: gastown.Message{}, response: SendMailResponse{}},
+		{pattern: "GET /api/v1/town/mail/{address}/threads", handler: s.handleMailThreads, tag: "Mail",
+			summary: "An agent's mailbox grouped into threads", response: ThreadsResponse{}},
+	}
+}
+
+// registerRoutes sets up all API endpoints, then the web UI.
+func (s *Server) registerRoutes() {
+	for _, rt := range s.routes() {
+		handler := rt.handler
+		if rt.conditional {
+			handler = s.conditional(handler)
+		}
+		s.mux.HandleFunc(rt.pattern, handler)
+	}
+
+	// Static files — catch-all after API routes
+	s.serveStaticFiles()
+}
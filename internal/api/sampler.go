@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/store"
+)
+
+// DefaultSampleInterval is how often metrics are sampled into the store.
+const DefaultSampleInterval = time.Minute
+
+// DefaultRetention is how long metric samples are kept.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// pruneInterval is how often expired samples are dropped from the store.
+const pruneInterval = time.Hour
+
+// sampleInterval returns the configured sample interval or the default.
+func (s *Server) sampleInterval() time.Duration {
+	if s.config.SampleInterval <= 0 {
+		return DefaultSampleInterval
+	}
+	return s.config.SampleInterval
+}
+
+// observed returns the periods gvid was watching the town. A late tick is
+// tolerated, so only gaps longer than two sample intervals count as missed.
+func (s *Server) observed() []store.Interval {
+	return s.store.Observed(2 * s.sampleInterval())
+}
+
+// runSampler records metric samples on the configured interval until ctx
+// is canceled. It brackets the run with start and stop markers and records
+// a heartbeat each tick, so history can tell when gvid was not watching.
+// Expired samples are pruned at startup and every pruneInterval.
+func (s *Server) runSampler(ctx context.Context) {
+	ticker := time.NewTicker(s.sampleInterval())
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	s.recordMarker(store.MarkerStart)
+	s.prune(ctx)
+	s.sample(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.recordMarker(store.MarkerStop)
+			return
+		case <-ticker.C:
+			s.recordMarker(store.MarkerHeartbeat)
+			s.sample(ctx)
+		case <-pruneTicker.C:
+			s.prune(ctx)
+		}
+	}
+}
+
+// prune drops samples older than the retention window and the progress
+// series of convoys that have completed or are no longer listed. Convoy
+// series are kept while the convoy list cannot be read.
+func (s *Server) prune(ctx context.Context) {
+	retention := s.config.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+
+	var open map[string]bool
+	if snap, err := s.gtCache.Snapshot(ctx); err == nil && snap.Err == nil && snap.ConvoysErr == nil {
+		open = make(map[string]bool)
+		for _, convoy := range snap.Town.Convoys {
+			if convoy.Status != gastown.ConvoyStatusComplete {
+				open[convoy.ID] = true
+			}
+		}
+	}
+	closed := func(name string) bool {
+		id, ok := convoyProgressID(name)
+		return ok && open != nil && !open[id]
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.store.PruneSeries(time.Now().Add(-retention), closed); err != nil {
+		slog.Warn("Failed to prune metric samples", "error", err)
+	}
+}
+
+// convoyProgressID returns the convoy ID of a convoy.<id>.progress series.
+func convoyProgressID(name string) (string, bool) {
+	rest, ok := strings.CutPrefix(name, "convoy.")
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(rest, ".progress")
+}
+
+// recordMarker appends an observation marker, logging failures.
+func (s *Server) recordMarker(event string) {
+	if err := s.store.RecordMarker(event, time.Now()); err != nil {
+		slog.Warn("Failed to record observation marker", "event", event, "error", err)
+	}
+}
+
+// sample collects board and town metrics and appends them to the store.
+// Sources that fail are skipped so one outage does not lose the others.
+func (s *Server) sample(ctx context.Context) {
+	values := make(map[string]float64)
+
+	if board, err := s.adapter.Board(ctx); err == nil {
+		values["issues.total"] = float64(board.Total)
+		for _, col := range board.Columns {
+			values["board."+string(col.Status)] = float64(col.Count)
+		}
+	}
+
+	if snap, err := s.gtCache.Snapshot(ctx); err == nil && snap.Err == nil {
+		counts := map[gastown.AgentStatus]int{}
+		for _, agent := range snap.Agents {
+			counts[agent.Status]++
+		}
+		values["agents.total"] = float64(len(snap.Agents))
+		for _, status := range []gastown.AgentStatus{
+			gastown.StatusActive, gastown.StatusIdle, gastown.StatusStuck, gastown.StatusOffline,
+		} {
+			values["agents."+string(status)] = float64(counts[status])
+		}
+
+		var active, blocked int
+		for _, convoy := range snap.Town.Convoys {
+			blocked += convoy.Blocked
+			// Completed convoys' series are pruned, so stop sampling them
+			if convoy.Status != gastown.ConvoyStatusComplete {
+				active++
+				values["convoy."+convoy.ID+".progress"] = float64(convoy.Progress)
+			}
+		}
+		values["convoys.active"] = float64(active)
+		values["convoys.blocked_issues"] = float64(blocked)
+
+		var molBlocked int
+		for _, mol := range snap.Molecules {
+			if mol.Status == gastown.MolStatusBlocked || mol.Status == gastown.MolStatusFailed {
+				molBlocked++
+			}
+		}
+		values["molecules.blocked"] = float64(molBlocked)
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.store.RecordSamples(time.Now(), values); err != nil {
+		slog.Warn("Failed to record metric samples", "error", err)
+	}
+}
@@ -4,12 +4,18 @@ package api
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/metrics"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/store"
 )
 
 // Config holds server configuration.
@@ -19,16 +25,73 @@ type Config struct {
 	CORSOrigins []string
 	Version     string
 	TownRoot    string // Gas Town workspace root (default: ~/gt)
+
+	// Runner runs the gt, tmux and git commands the town is read with.
+	// Nil uses gastown.DefaultRunner.
+	Runner gastown.Runner
+
+	// HealthRules classify agents as active, idle or stuck per role.
+	// Nil uses gastown.DefaultHealthRules.
+	HealthRules gastown.HealthRules
+
+	// TownRefresh is how often the town snapshot is rescanned in the background.
+	TownRefresh time.Duration
+
+	// WriteEnabled allows endpoints that act on the town, such as sending
+	// mail or nudging agents. Every action is recorded to the audit log.
+	WriteEnabled bool
+
+	// AuditLog is the file actions are appended to as JSON lines.
+	// Empty logs them through the server log.
+	AuditLog string
+
+	// DataDir is where agent history and metric samples are persisted.
+	// Empty keeps them in memory.
+	DataDir string
+
+	// SampleInterval is how often metric series are sampled.
+	SampleInterval time.Duration
+
+	// Retention is how long metric samples are kept.
+	Retention time.Duration
+
+	// SSEHeartbeat is how often event streams receive a heartbeat.
+	SSEHeartbeat time.Duration
+
+	// TLSCert and TLSKey are PEM files to serve HTTPS with. Both empty
+	// serves plain HTTP.
+	TLSCert string
+	TLSKey  string
+
+	// ClientCA is a PEM bundle of CAs; when set, clients must present a
+	// certificate signed by one of them.
+	ClientCA string
+
+	// Socket is the path of a Unix socket to listen on, in addition to
+	// TCP unless DisableTCP is set. SocketMode defaults to 0600; SocketOwner
+	// is an optional "user[:group]".
+	Socket      string
+	SocketMode  os.FileMode
+	SocketOwner string
+	DisableTCP  bool
+
+	// Tokens are the bearer tokens accepted by the API, usually loaded
+	// with LoadTokens. Nil disables authentication.
+	Tokens Tokens
 }
 
 // DefaultConfig returns configuration with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		Port:        7070,
-		Host:        "localhost",
-		CORSOrigins: []string{"http://localhost:5173"},
-		Version:     "0.1.0",
-		TownRoot:    "", // Empty means use default ~/gt
+		Port:           7070,
+		Host:           "localhost",
+		CORSOrigins:    []string{"http://localhost:5173"},
+		Version:        "0.1.0",
+		TownRoot:       "", // Empty means use default ~/gt
+		TownRefresh:    gastown.DefaultRefreshInterval,
+		SampleInterval: DefaultSampleInterval,
+		Retention:      DefaultRetention,
+		SSEHeartbeat:   DefaultSSEHeartbeat,
 	}
 }
 
@@ -37,90 +100,148 @@ type Server struct {
 	config    Config
 	adapter   beads.Adapter
 	gtAdapter gastown.Adapter
+	gtCache   *gastown.CachedAdapter
+	gtControl gastown.Controller
+	auditLog  *AuditLog
+	store     *store.Store
 	mux       *http.ServeMux
 	sse       *SSEBroker
+	metrics   *serverMetrics
+	live      atomic.Pointer[liveConfig]
+
+	validators *validators
+
+	mu         sync.Mutex
+	httpServer *http.Server
+	certs      *certReloader // Set by Start when serving TLS
+	cancel     context.CancelFunc
+	background sync.WaitGroup
+	stopping   chan struct{} // Closed when Shutdown begins
+	stopOnce   sync.Once
 }
 
 // NewServer creates a new API server.
 func NewServer(config Config, adapter beads.Adapter) *Server {
+	fsAdapter := gastown.NewFSAdapter(config.TownRoot)
+	if config.Runner != nil {
+		fsAdapter = gastown.NewFSAdapterWithRunner(config.TownRoot, config.Runner)
+	}
+	if config.HealthRules != nil {
+		fsAdapter.SetHealthRules(config.HealthRules)
+	}
+	gtCache := gastown.NewCachedAdapter(fsAdapter, config.TownRefresh)
+
+	auditLog, err := NewAuditLog(config.AuditLog)
+	if err != nil {
+		slog.Warn("Recording actions to the server log", "error", err)
+		auditLog, _ = NewAuditLog("")
+	}
+
+	st, err := store.Open(config.DataDir)
+	if err != nil {
+		slog.Warn("Keeping history in memory", "error", err)
+		st, _ = store.Open("")
+	}
+
 	s := &Server{
 		config:    config,
 		adapter:   adapter,
-		gtAdapter: gastown.NewFSAdapter(config.TownRoot),
+		gtAdapter: gtCache,
+		gtCache:   gtCache,
+		gtControl: gtCache,
+		auditLog:  auditLog,
+		store:     st,
 		mux:       http.NewServeMux(),
-		sse:       NewSSEBroker(),
+		sse:       NewSSEBroker(config.SSEHeartbeat),
+		stopping:  make(chan struct{}),
+
+		validators: newValidators(),
 	}
+	s.live.Store(newLiveConfig(config))
+	s.metrics = s.newServerMetrics(metrics.NewRegistry())
+	s.sse.onEvict = func() { s.metrics.sseEvictions.Inc() }
+	if observable, ok := adapter.(interface{ Observe(beads.Observer) }); ok {
+		observable.Observe(s.metrics.observeBD)
+	}
+	gtCache.OnRefresh(s.recordAgentHistory)
 	s.registerRoutes()
 	return s
 }
 
-// registerRoutes sets up all API endpoints.
-func (s *Server) registerRoutes() {
-	// Health check
-	s.mux.HandleFunc("GET /api/v1/health", s.handleHealth)
-
-	// Beads - Issues
-	s.mux.HandleFunc("GET /api/v1/issues", s.handleListIssues)
-	s.mux.HandleFunc("GET /api/v1/issues/{id}", s.handleGetIssue)
-
-	// Beads - Board
-	s.mux.HandleFunc("GET /api/v1/board", s.handleBoard)
-
-	// Beads - Graph
-	s.mux.HandleFunc("GET /api/v1/graph", s.handleGraph)
-
-	// SSE Events
-	s.mux.HandleFunc("GET /api/v1/events", s.handleEvents)
-
-	// Gas Town - Town
-	s.mux.HandleFunc("GET /api/v1/town", s.handleTown)
-	s.mux.HandleFunc("GET /api/v1/town/status", s.handleTownStatus)
-
-	// Gas Town - Rigs
-	s.mux.HandleFunc("GET /api/v1/town/rigs", s.handleRigs)
-	s.mux.HandleFunc("GET /api/v1/town/rigs/{name}", s.handleRig)
-
-	// Gas Town - Agents
-	s.mux.HandleFunc("GET /api/v1/town/agents", s.handleAgents)
-
-	// Gas Town - Convoys
-	s.mux.HandleFunc("GET /api/v1/town/convoys", s.handleConvoys)
-	s.mux.HandleFunc("GET /api/v1/town/convoys/{id}", s.handleConvoy)
-
-	// Gas Town - Molecules
-	s.mux.HandleFunc("GET /api/v1/town/molecules", s.handleMolecules)
-	s.mux.HandleFunc("GET /api/v1/town/molecules/{id}", s.handleMolecule)
-
-	// Gas Town - Mail
-	s.mux.HandleFunc("GET /api/v1/town/mail/{address}", s.handleMail)
-
-	// Static files — catch-all after API routes
-	s.serveStaticFiles()
-}
-
 // Handler returns the HTTP handler with middleware applied.
 func (s *Server) Handler() http.Handler {
-	return s.corsMiddleware(s.loggingMiddleware(s.mux))
+	return s.corsMiddleware(s.loggingMiddleware(s.compressMiddleware(s.authMiddleware(s.mux))))
 }
 
-// Start starts the HTTP server.
+// Start starts the HTTP server on the configured TCP address and Unix
+// socket, or on the sockets passed by systemd. TCP listeners serve HTTPS
+// when a certificate is configured.
 func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-	log.Printf("Starting Gastown Viewer Intent daemon on %s", addr)
-	log.Printf("API: http://%s/api/v1/", addr)
+	tlsCfg, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	lns, err := s.listeners(tlsCfg)
+	if err != nil {
+		return err
+	}
+
+	for _, ln := range lns {
+		scheme := "http"
+		if ln.Addr().Network() == "unix" {
+			scheme = "unix"
+		} else if tlsCfg != nil {
+			scheme = "https"
+		}
+		slog.Info("Listening", "addr", ln.Addr().String(), "api", fmt.Sprintf("%s://%s/api/v1/", scheme, ln.Addr()))
+	}
+	if tlsCfg != nil && tlsCfg.ClientCAs != nil {
+		slog.Info("Requiring client certificates", "client_ca", s.config.ClientCA)
+	}
 
 	// Start SSE broker
 	go s.sse.Start()
 
+	// Refresh the town snapshot and sample metrics in the background
+	ctx, cancel := context.WithCancel(context.Background())
+	s.background.Add(2)
+	go func() {
+		defer s.background.Done()
+		s.gtCache.Start(ctx)
+	}()
+	go func() {
+		defer s.background.Done()
+		s.runSampler(ctx)
+	}()
+
+	// Streaming endpoints lift WriteTimeout per request; see sseStream.
 	server := &http.Server{
-		Addr:         addr,
 		Handler:      s.Handler(),
+		ConnContext:  markUnixConn,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		ErrorLog:     slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	s.mu.Lock()
+	s.httpServer = server
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	select {
+	case <-s.stopping:
+		// Shutdown ran before the server was registered
+		cancel()
+		for _, ln := range lns {
+			ln.Close()
+		}
+		return http.ErrServerClosed
+	default:
 	}
 
-	return server.ListenAndServe()
+	return serveAll(server.Serve, lns)
 }
 
 // corsMiddleware adds CORS headers for development.
@@ -130,7 +251,7 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 
 		// Check if origin is allowed
 		allowed := false
-		for _, o := range s.config.CORSOrigins {
+		for _, o := range s.settings().corsOrigins {
 			if o == origin || o == "*" {
 				allowed = true
 				break
@@ -139,8 +260,9 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 
 		if allowed {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, If-None-Match, If-Modified-Since")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, ETag, Last-Modified")
 		}
 
 		// Handle preflight
@@ -153,15 +275,70 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// loggingMiddleware logs requests.
+// loggingMiddleware assigns each request an ID, logs it once complete and
+// records its count and latency. A valid X-Request-ID from the client is
+// kept; otherwise a new one is generated. The ID is echoed in the response
+// and carried in the request context to the bd, gt and tmux calls it makes.
 func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
+
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(logging.RequestIDHeader, id)
+		r = r.WithContext(logging.WithRequestID(r.Context(), id))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		elapsed := time.Since(start)
+
+		slog.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", routeOf(r)),
+			slog.Int("status", rec.status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Float64("duration_ms", logging.Milliseconds(elapsed)),
+			slog.String("remote", r.RemoteAddr),
+		)
+		s.metrics.observeRequest(r, rec.status, elapsed)
 	})
 }
 
+// statusRecorder captures the status code and body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status before passing it on.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written before passing them on.
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // checkBeadsInitialized verifies beads is ready, returns false and writes error if not.
 func (s *Server) checkBeadsInitialized(w http.ResponseWriter, r *http.Request) bool {
 	ctx := r.Context()
@@ -182,8 +359,47 @@ func (s *Server) checkBeadsInitialized(w http.ResponseWriter, r *http.Request) b
 	return true
 }
 
-// Shutdown gracefully shuts down the server.
+// Shutdown stops the server gracefully. It stops accepting connections,
+// sends a final shutdown event to SSE clients and closes their streams,
+// waits for in-flight requests and their bd calls, then stops background
+// refreshes and sampling. When ctx expires first, remaining connections
+// are closed and ctx's error is returned.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
 	s.sse.Stop()
-	return nil
+
+	s.mu.Lock()
+	server, cancel := s.httpServer, s.cancel
+	s.mu.Unlock()
+
+	var err error
+	if server != nil {
+		if err = server.Shutdown(ctx); err != nil {
+			server.Close()
+		}
+	}
+
+	if cancel != nil {
+		cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	if closeErr := s.store.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if closeErr := s.auditLog.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
 }
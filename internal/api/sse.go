@@ -2,8 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
@@ -11,19 +12,39 @@ import (
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
 )
 
+// DefaultSSEHeartbeat is how often a heartbeat event is sent to SSE clients.
+const DefaultSSEHeartbeat = 30 * time.Second
+
+// sseWriteTimeout bounds each write to a streaming client. A client that
+// cannot take an event within it is disconnected.
+const sseWriteTimeout = 10 * time.Second
+
+// sseClientBuffer is how many events may queue for one client before it
+// is considered too slow and evicted.
+const sseClientBuffer = 10
+
 // SSEBroker manages SSE client connections and event broadcasting.
 type SSEBroker struct {
+	heartbeat  time.Duration
+	onEvict    func()
 	clients    map[chan []byte]bool
 	register   chan chan []byte
 	unregister chan chan []byte
 	broadcast  chan []byte
 	done       chan struct{}
+	stopOnce   sync.Once
+	stopped    bool
 	mu         sync.RWMutex
 }
 
-// NewSSEBroker creates a new SSE broker.
-func NewSSEBroker() *SSEBroker {
+// NewSSEBroker creates a new SSE broker sending heartbeats on the given
+// interval, or DefaultSSEHeartbeat if it is not positive.
+func NewSSEBroker(heartbeat time.Duration) *SSEBroker {
+	if heartbeat <= 0 {
+		heartbeat = DefaultSSEHeartbeat
+	}
 	return &SSEBroker{
+		heartbeat:  heartbeat,
 		clients:    make(map[chan []byte]bool),
 		register:   make(chan chan []byte),
 		unregister: make(chan chan []byte),
@@ -34,7 +55,7 @@ func NewSSEBroker() *SSEBroker {
 
 // Start begins the broker's event loop.
 func (b *SSEBroker) Start() {
-	heartbeatTicker := time.NewTicker(30 * time.Second)
+	heartbeatTicker := time.NewTicker(b.heartbeat)
 	defer heartbeatTicker.Stop()
 
 	for {
@@ -44,9 +65,14 @@ func (b *SSEBroker) Start() {
 
 		case client := <-b.register:
 			b.mu.Lock()
-			b.clients[client] = true
+			if b.stopped {
+				close(client)
+			} else {
+				b.clients[client] = true
+			}
+			count := len(b.clients)
 			b.mu.Unlock()
-			log.Printf("SSE client connected (%d total)", len(b.clients))
+			slog.Debug("SSE client connected", "clients", count)
 
 		case client := <-b.unregister:
 			b.mu.Lock()
@@ -54,19 +80,12 @@ func (b *SSEBroker) Start() {
 				delete(b.clients, client)
 				close(client)
 			}
+			count := len(b.clients)
 			b.mu.Unlock()
-			log.Printf("SSE client disconnected (%d total)", len(b.clients))
+			slog.Debug("SSE client disconnected", "clients", count)
 
 		case msg := <-b.broadcast:
-			b.mu.RLock()
-			for client := range b.clients {
-				select {
-				case client <- msg:
-				default:
-					// Client buffer full, skip
-				}
-			}
-			b.mu.RUnlock()
+			b.deliver(msg)
 
 		case <-heartbeatTicker.C:
 			b.sendHeartbeat()
@@ -74,39 +93,107 @@ func (b *SSEBroker) Start() {
 	}
 }
 
-// Stop shuts down the broker.
-func (b *SSEBroker) Stop() {
-	close(b.done)
+// deliver queues msg for every client. A client whose buffer is full has
+// stopped reading in time; it is disconnected rather than silently missing
+// events, so it can reconnect and resynchronize.
+func (b *SSEBroker) deliver(msg []byte) {
 	b.mu.Lock()
+	var evicted int
 	for client := range b.clients {
-		close(client)
+		select {
+		case client <- msg:
+		default:
+			delete(b.clients, client)
+			close(client)
+			evicted++
+		}
 	}
-	b.clients = make(map[chan []byte]bool)
+	remaining := len(b.clients)
 	b.mu.Unlock()
+
+	for i := 0; i < evicted; i++ {
+		slog.Warn("SSE client evicted: buffer full", "clients", remaining)
+		if b.onEvict != nil {
+			b.onEvict()
+		}
+	}
+}
+
+// Stop sends a shutdown event to every client, disconnects them and
+// shuts down the broker. It is safe to call more than once.
+func (b *SSEBroker) Stop() {
+	b.stopOnce.Do(func() {
+		msg, err := formatEvent(model.NewShutdownEvent())
+		if err != nil {
+			slog.Error("Failed to encode SSE event", "error", err)
+		}
+
+		close(b.done)
+		b.mu.Lock()
+		b.stopped = true
+		for client := range b.clients {
+			if msg != nil {
+				select {
+				case client <- msg:
+				default:
+					// Client buffer full; it still sees the stream end
+				}
+			}
+			close(client)
+		}
+		b.clients = make(map[chan []byte]bool)
+		b.mu.Unlock()
+	})
 }
 
 // Subscribe registers a new client and returns their message channel.
+// After Stop the channel is returned already closed.
 func (b *SSEBroker) Subscribe() chan []byte {
-	client := make(chan []byte, 10)
-	b.register <- client
+	client := make(chan []byte, sseClientBuffer)
+	select {
+	case b.register <- client:
+	case <-b.done:
+		close(client)
+	}
 	return client
 }
 
 // Unsubscribe removes a client.
 func (b *SSEBroker) Unsubscribe(client chan []byte) {
-	b.unregister <- client
+	select {
+	case b.unregister <- client:
+	case <-b.done:
+	}
+}
+
+// ClientCount returns the number of connected clients.
+func (b *SSEBroker) ClientCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.clients)
 }
 
 // Broadcast sends an event to all connected clients.
 func (b *SSEBroker) Broadcast(event model.Event) {
-	data, err := json.Marshal(event.Data)
+	msg, err := formatEvent(event)
 	if err != nil {
-		log.Printf("SSE marshal error: %v", err)
+		slog.Error("Failed to encode SSE event", "error", err)
 		return
 	}
 
-	msg := fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, data)
-	b.broadcast <- []byte(msg)
+	select {
+	case b.broadcast <- msg:
+	case <-b.done:
+	}
+}
+
+// formatEvent encodes an event in the SSE wire format.
+func formatEvent(event model.Event) ([]byte, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, data)), nil
 }
 
 // sendHeartbeat sends a heartbeat event to all clients.
@@ -114,20 +201,51 @@ func (b *SSEBroker) sendHeartbeat() {
 	b.Broadcast(model.NewHeartbeat())
 }
 
-// handleEvents handles GET /api/v1/events (SSE endpoint).
-func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
-	// Check if SSE is supported
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+// sseStream writes events to a streaming response. The server-wide write
+// timeout is lifted for the stream and each write gets its own deadline
+// instead, so long-lived streams stay open while stuck clients are dropped.
+type sseStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEStream sets the SSE headers on w. It returns false after writing an
+// error if w cannot stream.
+func newSSEStream(w http.ResponseWriter) (*sseStream, bool) {
+	if _, ok := w.(http.Flusher); !ok {
 		writeError(w, http.StatusInternalServerError, "SSE_NOT_SUPPORTED",
 			"Streaming not supported")
-		return
+		return nil, false
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Warn("Failed to clear SSE write deadline", "error", err)
 	}
 
-	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
+	return &sseStream{w: w, rc: rc}, true
+}
+
+// write sends msg and flushes it within sseWriteTimeout.
+func (s *sseStream) write(msg []byte) error {
+	if err := s.rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := s.w.Write(msg); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// handleEvents handles GET /api/v1/events (SSE endpoint).
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	stream, ok := newSSEStream(w)
+	if !ok {
+		return
+	}
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Subscribe to events
@@ -135,8 +253,9 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	defer s.sse.Unsubscribe(client)
 
 	// Send initial connection event
-	fmt.Fprintf(w, "event: connected\ndata: {\"message\":\"Connected to Gastown Viewer Intent\"}\n\n")
-	flusher.Flush()
+	if err := stream.write([]byte("event: connected\ndata: {\"message\":\"Connected to Gastown Viewer Intent\"}\n\n")); err != nil {
+		return
+	}
 
 	// Listen for events or client disconnect
 	ctx := r.Context()
@@ -148,8 +267,10 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
-			_, _ = w.Write(msg)
-			flusher.Flush()
+			if err := stream.write(msg); err != nil {
+				slog.InfoContext(ctx, "SSE client dropped", "error", err)
+				return
+			}
 		}
 	}
 }
@@ -163,3 +284,8 @@ func (s *Server) NotifyIssueCreated(id, title string, status model.Status) {
 func (s *Server) NotifyIssueUpdated(id string, status, previousStatus model.Status) {
 	s.sse.Broadcast(model.NewIssueUpdatedEvent(id, status, previousStatus))
 }
+
+// NotifyAgentStatus broadcasts an agent_status event.
+func (s *Server) NotifyAgentStatus(address, status, previousStatus, reason string) {
+	s.sse.Broadcast(model.NewAgentStatusEvent(address, status, previousStatus, reason))
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+func TestSSEBroker_EvictsSlowClients(t *testing.T) {
+	broker := NewSSEBroker(time.Hour)
+	var evictions atomic.Int32
+	broker.onEvict = func() { evictions.Add(1) }
+	go broker.Start()
+	defer broker.Stop()
+
+	slow := broker.Subscribe()
+	for i := 0; i <= sseClientBuffer; i++ {
+		broker.Broadcast(model.NewHeartbeat())
+	}
+
+	// Nothing reads from the client, so the last broadcast overflows it.
+	deadline := time.Now().Add(2 * time.Second)
+	for broker.ClientCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Slow client was not evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if evictions.Load() != 1 {
+		t.Errorf("Expected 1 eviction, got %d", evictions.Load())
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != sseClientBuffer {
+		t.Errorf("Expected %d buffered events before eviction, got %d", sseClientBuffer, received)
+	}
+	broker.Unsubscribe(slow)
+}
+
+func TestEventsOutliveWriteTimeout(t *testing.T) {
+	config := testConfig()
+	config.SSEHeartbeat = 50 * time.Millisecond
+	server := NewServer(config, beads.NewCLIAdapterWithExecutor("", beads.NewMockExecutor()))
+	go server.sse.Start()
+	defer server.sse.Stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := &http.Server{
+		Handler:      server.Handler(),
+		WriteTimeout: 100 * time.Millisecond,
+		ErrorLog:     log.New(io.Discard, "", 0),
+	}
+	go hs.Serve(ln)
+	defer hs.Close()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/api/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Heartbeats keep arriving well past the server's write timeout.
+	reader := bufio.NewReader(resp.Body)
+	start := time.Now()
+	heartbeats := 0
+	for time.Since(start) < 400*time.Millisecond {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Stream ended after %s: %v", time.Since(start), err)
+		}
+		if strings.HasPrefix(line, "event: heartbeat") {
+			heartbeats++
+		}
+	}
+	if heartbeats < 3 {
+		t.Errorf("Expected at least 3 heartbeats, got %d", heartbeats)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// certReloader serves a certificate that can be replaced while running,
+// so renewed certificates are picked up without dropping connections.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// newCertReloader loads the key pair once and returns the reloader.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the key pair from disk. On failure the previous
+// certificate stays in use.
+func (r *certReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// tlsConfig builds the server TLS configuration from the config, or
+// returns nil when TLS is not configured.
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	cfg := s.config
+	if cfg.TLSCert == "" && cfg.TLSKey == "" {
+		if cfg.ClientCA != "" {
+			return nil, fmt.Errorf("client CA requires a TLS certificate and key")
+		}
+		return nil, nil
+	}
+	if cfg.TLSCert == "" || cfg.TLSKey == "" {
+		return nil, fmt.Errorf("both a TLS certificate and key are required")
+	}
+
+	certs, err := newCertReloader(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.certs = certs
+	s.mu.Unlock()
+
+	tlsCfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+
+	if cfg.ClientCA != "" {
+		pool, err := loadCertPool(cfg.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsCfg, nil
+}
+
+// ReloadCertificates re-reads the TLS key pair. It does nothing when the
+// server is not serving TLS.
+func (s *Server) ReloadCertificates() error {
+	// SIGHUP can arrive before Start has set up TLS
+	s.mu.Lock()
+	certs := s.certs
+	s.mu.Unlock()
+
+	if certs == nil {
+		return nil
+	}
+	if err := certs.Reload(); err != nil {
+		return err
+	}
+	slog.Info("Reloaded TLS certificate", "cert", certs.certFile)
+	return nil
+}
+
+// CheckTLS loads the configured certificate, key and client CA without
+// starting a server, so configuration errors surface before a restart.
+func CheckTLS(cfg Config) error {
+	if cfg.TLSCert != "" || cfg.TLSKey != "" {
+		if _, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey); err != nil {
+			return fmt.Errorf("failed to load TLS key pair: %w", err)
+		}
+	}
+	if cfg.ClientCA != "" {
+		if _, err := loadCertPool(cfg.ClientCA); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCertPool reads PEM certificates from path into a pool.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/beads"
+)
+
+// testCA issues certificates for TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gvid test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate and key for cn to dir and returns their paths.
+func (ca *testCA) issue(t *testing.T, dir, cn string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath := filepath.Join(dir, cn+".crt")
+	keyPath := filepath.Join(dir, cn+".key")
+	writeTestFile(t, certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeTestFile(t, keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certPath, keyPath
+}
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caPath := filepath.Join(dir, "ca.pem")
+	writeTestFile(t, caPath, ca.pem)
+	serverCert, serverKey := ca.issue(t, dir, "server", 2, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "client", 3, x509.ExtKeyUsageClientAuth)
+
+	config := testConfig()
+	config.TLSCert = serverCert
+	config.TLSKey = serverKey
+	config.ClientCA = caPath
+	server := NewServer(config, beads.NewCLIAdapter(""))
+
+	tlsCfg, err := server.tlsConfig()
+	if err != nil {
+		t.Fatalf("tlsConfig() returned error: %v", err)
+	}
+
+	// httptest.Server.StartTLS would install its own certificate, which
+	// takes precedence over GetCertificate, so serve on a TLS listener.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := &http.Server{Handler: server.Handler(), TLSConfig: tlsCfg, ErrorLog: log.New(io.Discard, "", 0)}
+	go hs.Serve(tls.NewListener(ln, tlsCfg))
+	defer hs.Close()
+	url := "https://" + ln.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	pair, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{pair},
+	}}}
+	resp, err := withCert.Get(url + "/api/v1/health")
+	if err != nil {
+		t.Fatalf("Request with client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+
+	withoutCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if resp, err := withoutCert.Get(url + "/api/v1/health"); err == nil {
+		resp.Body.Close()
+		t.Error("Expected request without client certificate to fail")
+	}
+
+	// Replace the server certificate on disk and reload it.
+	ca.issue(t, dir, "server", 4, x509.ExtKeyUsageServerAuth)
+	if err := server.ReloadCertificates(); err != nil {
+		t.Fatalf("ReloadCertificates() returned error: %v", err)
+	}
+	cert, err := server.certs.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.SerialNumber.Int64() != 4 {
+		t.Errorf("Expected reloaded certificate serial 4, got %d", leaf.SerialNumber.Int64())
+	}
+
+	// A broken key pair keeps the current certificate.
+	writeTestFile(t, serverKey, []byte("garbage"))
+	if err := server.ReloadCertificates(); err == nil {
+		t.Error("Expected reload of a broken key pair to fail")
+	}
+	if cur, _ := server.certs.GetCertificate(nil); cur != cert {
+		t.Error("Expected the previous certificate to stay in use")
+	}
+}
+
+func TestReloadCertificatesBeforeStart(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := newTestCA(t).issue(t, dir, "server", 2, x509.ExtKeyUsageServerAuth)
+
+	config := testConfig()
+	config.TLSCert = serverCert
+	config.TLSKey = serverKey
+	server := NewServer(config, beads.NewCLIAdapter(""))
+
+	// SIGHUP may be handled while Start is still setting up TLS
+	done := make(chan error)
+	go func() { done <- server.ReloadCertificates() }()
+	if _, err := server.tlsConfig(); err != nil {
+		t.Fatalf("tlsConfig() returned error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ReloadCertificates() returned error: %v", err)
+	}
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	for _, config := range []Config{
+		{TLSCert: "server.crt"},
+		{ClientCA: "ca.pem"},
+	} {
+		server := &Server{config: config}
+		if _, err := server.tlsConfig(); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
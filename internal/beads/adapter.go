@@ -8,7 +8,8 @@ import (
 )
 
 // Adapter defines the interface for interacting with Beads.
-// All methods shell out to the bd CLI and parse JSON output.
+// Methods shell out to the bd CLI and parse JSON output, except
+// StatusHistory, which reads the interactions log bd writes.
 type Adapter interface {
 	// ListIssues returns all issues matching the optional filter.
 	ListIssues(ctx context.Context, filter model.IssueFilter) ([]model.Issue, error)
@@ -27,6 +28,10 @@ type Adapter interface {
 
 	// Version returns the bd CLI version.
 	Version(ctx context.Context) (string, error)
+
+	// StatusHistory returns issue status changes from the interactions
+	// timeline, oldest first.
+	StatusHistory(ctx context.Context) ([]StatusChange, error)
 }
 
 // CLIAdapter implements Adapter by shelling out to the bd CLI.
@@ -52,6 +57,12 @@ func NewCLIAdapterWithExecutor(workDir string, executor Executor) *CLIAdapter {
 	}
 }
 
+// Observe reports every subsequent bd call to fn. It must be called before
+// the adapter is shared between goroutines.
+func (a *CLIAdapter) Observe(fn Observer) {
+	a.executor = &observingExecutor{Executor: a.executor, observe: fn}
+}
+
 // ListIssues implements Adapter.ListIssues.
 func (a *CLIAdapter) ListIssues(ctx context.Context, filter model.IssueFilter) ([]model.Issue, error) {
 	args := []string{"list", "--json"}
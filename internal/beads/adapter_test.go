@@ -3,6 +3,7 @@ package beads
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
 )
@@ -190,3 +191,38 @@ func TestCLIAdapterBDNotFound(t *testing.T) {
 		t.Errorf("expected BDNotFoundError, got %T: %v", err, err)
 	}
 }
+
+func TestCLIAdapterObserve(t *testing.T) {
+	mock := NewMockExecutor()
+	mock.SetResponse("list --json", []byte(`[]`))
+	mock.SetError("show", &NotFoundError{ID: "nope"})
+
+	adapter := NewCLIAdapterWithExecutor("", mock)
+
+	type call struct {
+		subcommand string
+		failed     bool
+	}
+	var calls []call
+	adapter.Observe(func(subcommand string, d time.Duration, err error) {
+		calls = append(calls, call{subcommand, err != nil})
+	})
+
+	ctx := context.Background()
+	if _, err := adapter.ListIssues(ctx, model.NewIssueFilter()); err != nil {
+		t.Fatalf("ListIssues failed: %v", err)
+	}
+	if _, err := adapter.GetIssue(ctx, "nope"); err == nil {
+		t.Fatal("Expected error for missing issue")
+	}
+
+	want := []call{{"list", false}, {"show", true}}
+	if len(calls) != len(want) {
+		t.Fatalf("Expected %d observed calls, got %+v", len(want), calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("Call %d: expected %+v, got %+v", i, want[i], calls[i])
+		}
+	}
+}
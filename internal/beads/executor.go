@@ -5,6 +5,9 @@ import (
 	"context"
 	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
 )
 
 // Executor defines the interface for executing bd commands.
@@ -15,7 +18,8 @@ type Executor interface {
 // DefaultExecutor implements Executor by shelling out to the bd binary.
 type DefaultExecutor struct{}
 
-// Execute runs bd with the given arguments and returns stdout.
+// Execute runs bd with the given arguments and returns stdout. Each run is
+// logged with its duration and exit code under the request ID in ctx.
 func (e *DefaultExecutor) Execute(ctx context.Context, workDir string, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, "bd", args...)
 
@@ -27,7 +31,9 @@ func (e *DefaultExecutor) Execute(ctx context.Context, workDir string, args ...s
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	start := time.Now()
 	err := cmd.Run()
+	logging.Command(ctx, "bd", args, time.Since(start), stderr.String(), err)
 	if err != nil {
 		// Check for specific error conditions
 		stderrStr := stderr.String()
@@ -59,6 +65,30 @@ func (e *DefaultExecutor) Execute(ctx context.Context, workDir string, args ...s
 	return stdout.Bytes(), nil
 }
 
+// Observer is called after every bd invocation with its subcommand, how
+// long it took and the error it returned, if any.
+type Observer func(subcommand string, duration time.Duration, err error)
+
+// observingExecutor reports each call of the wrapped executor to an Observer.
+type observingExecutor struct {
+	Executor
+	observe Observer
+}
+
+// Execute runs the wrapped executor and reports the call.
+func (e *observingExecutor) Execute(ctx context.Context, workDir string, args ...string) ([]byte, error) {
+	start := time.Now()
+	output, err := e.Executor.Execute(ctx, workDir, args...)
+
+	subcommand := ""
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	e.observe(subcommand, time.Since(start), err)
+
+	return output, err
+}
+
 // extractIDFromArgs attempts to extract an issue ID from command args.
 func extractIDFromArgs(args []string) string {
 	for i, arg := range args {
@@ -0,0 +1,149 @@
+package beads
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+// interactionsFile is the beads interactions timeline, relative to workDir.
+var interactionsFile = filepath.Join(".beads", "interactions.jsonl")
+
+// StatusChange is an issue moving to a new status, from the interactions log.
+type StatusChange struct {
+	IssueID string       `json:"issue_id"`
+	From    model.Status `json:"from,omitempty"`
+	To      model.Status `json:"to"`
+	Actor   string       `json:"actor,omitempty"`
+	At      time.Time    `json:"at"`
+}
+
+// bdInteraction is one line of interactions.jsonl. Older and newer bd
+// versions name the event kind and timestamp fields differently.
+type bdInteraction struct {
+	IssueID   string    `json:"issue_id"`
+	Kind      string    `json:"kind"`
+	EventType string    `json:"event_type"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	Status    string    `json:"status"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// statusChange converts a status or close interaction, returning false for
+// any other kind of interaction.
+func (bi *bdInteraction) statusChange() (StatusChange, bool) {
+	kind := bi.Kind
+	if kind == "" {
+		kind = bi.EventType
+	}
+
+	change := StatusChange{IssueID: bi.IssueID, Actor: bi.Actor, At: bi.CreatedAt}
+	if change.At.IsZero() {
+		change.At = bi.Timestamp
+	}
+
+	switch strings.ToLower(kind) {
+	case "status_changed", "status_change", "status":
+		to := bi.NewValue
+		if to == "" {
+			to = bi.Status
+		}
+		if to == "" {
+			return change, false
+		}
+		change.To = mapStatus(to)
+		if bi.OldValue != "" {
+			change.From = mapStatus(bi.OldValue)
+		}
+	case "closed", "close":
+		change.To = model.StatusDone
+	case "reopened", "reopen":
+		change.To = model.StatusPending
+	default:
+		return change, false
+	}
+
+	return change, change.IssueID != "" && !change.At.IsZero()
+}
+
+// ParseInteractions extracts status changes from interactions.jsonl content.
+func ParseInteractions(data []byte) ([]StatusChange, error) {
+	var changes []StatusChange
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var bi bdInteraction
+		if err := json.Unmarshal([]byte(line), &bi); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if change, ok := bi.statusChange(); ok {
+			changes = append(changes, change)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].At.Before(changes[j].At)
+	})
+	return changes, nil
+}
+
+// StatusHistory implements Adapter.StatusHistory. A repository without an
+// interactions log has no history.
+func (a *CLIAdapter) StatusHistory(ctx context.Context) ([]StatusChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.workDir, interactionsFile))
+	if os.IsNotExist(err) {
+		return []StatusChange{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := ParseInteractions(data)
+	if err != nil {
+		return nil, &ParseError{Command: "interactions", Err: err}
+	}
+	if changes == nil {
+		changes = []StatusChange{}
+	}
+	return changes, nil
+}
+
+// StartedTimes returns when each issue first entered in_progress.
+func StartedTimes(changes []StatusChange) map[string]time.Time {
+	started := make(map[string]time.Time)
+	for _, c := range changes {
+		if c.To != model.StatusInProgress {
+			continue
+		}
+		if first, ok := started[c.IssueID]; !ok || c.At.Before(first) {
+			started[c.IssueID] = c.At
+		}
+	}
+	return started
+}
@@ -0,0 +1,64 @@
+package beads
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+func TestParseInteractions(t *testing.T) {
+	input := []byte(`{"issue_id": "bd-1", "kind": "status_changed", "old_value": "open", "new_value": "in_progress", "created_at": "2026-01-02T10:00:00Z"}
+{"issue_id": "bd-1", "kind": "comment", "created_at": "2026-01-02T11:00:00Z"}
+
+{"issue_id": "bd-1", "event_type": "closed", "timestamp": "2026-01-03T10:00:00Z"}
+{"issue_id": "bd-2", "kind": "status_changed", "new_value": "in_progress", "created_at": "2026-01-01T09:00:00Z"}
+`)
+
+	changes, err := ParseInteractions(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 status changes, got %d", len(changes))
+	}
+	if changes[0].IssueID != "bd-2" {
+		t.Errorf("expected changes sorted by time, got %s first", changes[0].IssueID)
+	}
+	if changes[1].From != model.StatusPending || changes[1].To != model.StatusInProgress {
+		t.Errorf("unexpected change: %+v", changes[1])
+	}
+	if changes[2].To != model.StatusDone {
+		t.Errorf("expected close to map to done, got %s", changes[2].To)
+	}
+
+	started := StartedTimes(changes)
+	if len(started) != 2 || !started["bd-1"].Equal(changes[1].At) {
+		t.Errorf("unexpected started times: %v", started)
+	}
+}
+
+func TestCLIAdapterStatusHistory(t *testing.T) {
+	dir := t.TempDir()
+	adapter := NewCLIAdapterWithExecutor(dir, NewMockExecutor())
+
+	changes, err := adapter.StatusHistory(context.Background())
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected empty history without a log, got %v, %v", changes, err)
+	}
+
+	path := filepath.Join(dir, ".beads", "interactions.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := adapter.StatusHistory(context.Background()); !IsParseError(err) {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
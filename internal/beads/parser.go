@@ -16,6 +16,7 @@ type BDIssue struct {
 	Status          string        `json:"status"`
 	Priority        int           `json:"priority"`
 	IssueType       string        `json:"issue_type"`
+	Assignee        string        `json:"assignee,omitempty"`
 	CreatedAt       time.Time     `json:"created_at"`
 	UpdatedAt       time.Time     `json:"updated_at"`
 	ClosedAt        *time.Time    `json:"closed_at,omitempty"`
@@ -36,8 +37,11 @@ func (bi *BDIssue) ToModelIssue() model.Issue {
 		Description: bi.Description,
 		Status:      mapStatus(bi.Status),
 		Priority:    mapPriority(bi.Priority),
+		Type:        bi.IssueType,
+		Assignee:    bi.Assignee,
 		CreatedAt:   bi.CreatedAt,
 		UpdatedAt:   bi.UpdatedAt,
+		ClosedAt:    bi.ClosedAt,
 		Children:    []model.IssueSummary{},
 		Blocks:      []model.IssueSummary{},
 		BlockedBy:   []model.IssueSummary{},
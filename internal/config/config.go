@@ -0,0 +1,422 @@
+// Package config loads gvid settings from a YAML file and GVID_*
+// environment variables and turns them into an api.Config.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/api"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
+)
+
+// File is the on-disk configuration. Unset fields keep the defaults of
+// api.DefaultConfig.
+type File struct {
+	// Workspace is the directory bd runs in (default: current directory).
+	Workspace string `yaml:"workspace"`
+	// Town is the Gas Town workspace root (default: ~/gt).
+	Town string `yaml:"town"`
+
+	Listen      Listen    `yaml:"listen"`
+	TLS         TLS       `yaml:"tls"`
+	CORSOrigins []string  `yaml:"cors_origins"`
+	Auth        Auth      `yaml:"auth"`
+	Write       Write     `yaml:"write"`
+	DataDir     *string   `yaml:"data_dir"`
+	Intervals   Intervals `yaml:"intervals"`
+	Log         Log       `yaml:"log"`
+
+	// Health overrides status thresholds per agent role. Fields left out
+	// keep the role's built-in rule.
+	Health map[string]HealthRule `yaml:"health"`
+}
+
+// Listen configures where gvid accepts connections.
+type Listen struct {
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"`
+	Socket      string `yaml:"socket"`
+	SocketMode  string `yaml:"socket_mode"`
+	SocketOwner string `yaml:"socket_owner"`
+	DisableTCP  bool   `yaml:"disable_tcp"`
+}
+
+// TLS configures HTTPS and client certificates.
+type TLS struct {
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	ClientCA string `yaml:"client_ca"`
+}
+
+// Auth configures API tokens.
+type Auth struct {
+	TokenFile string `yaml:"token_file"`
+}
+
+// Write configures actions that change the town.
+type Write struct {
+	Enabled  bool   `yaml:"enabled"`
+	AuditLog string `yaml:"audit_log"`
+}
+
+// Intervals configures background timing.
+type Intervals struct {
+	TownRefresh     time.Duration `yaml:"town_refresh"`
+	Sample          time.Duration `yaml:"sample"`
+	Retention       time.Duration `yaml:"retention"`
+	SSEHeartbeat    time.Duration `yaml:"sse_heartbeat"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Log configures the server log.
+type Log struct {
+	// Level is debug, info, warn or error (default: info). Debug also
+	// logs every bd, gt and tmux call that succeeds.
+	Level string `yaml:"level"`
+}
+
+// HealthRule overrides parts of a role's gastown.HealthRule. Nil fields
+// keep the default; zero disables a threshold.
+type HealthRule struct {
+	StuckAfter       *time.Duration `yaml:"stuck_after"`
+	IdleAfter        *time.Duration `yaml:"idle_after"`
+	StuckWithoutHook *bool          `yaml:"stuck_without_hook"`
+	MaxCompaction    *int           `yaml:"max_compaction"`
+}
+
+// DefaultShutdownTimeout is used when intervals.shutdown_timeout is unset.
+const DefaultShutdownTimeout = 10 * time.Second
+
+// Load reads a YAML config file. Unknown keys are rejected so typos do not
+// go unnoticed.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// envVar binds a GVID_* variable to a field of File.
+type envVar struct {
+	name string
+	set  func(f *File, v string) error
+}
+
+func setString(field func(*File) *string) func(*File, string) error {
+	return func(f *File, v string) error {
+		*field(f) = v
+		return nil
+	}
+}
+
+func setBool(field func(*File) *bool) func(*File, string) error {
+	return func(f *File, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*field(f) = b
+		return nil
+	}
+}
+
+func setDuration(field func(*File) *time.Duration) func(*File, string) error {
+	return func(f *File, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(f) = d
+		return nil
+	}
+}
+
+// envVars lists the supported environment overrides.
+var envVars = []envVar{
+	{"GVID_WORKSPACE", setString(func(f *File) *string { return &f.Workspace })},
+	{"GVID_TOWN", setString(func(f *File) *string { return &f.Town })},
+	{"GVID_HOST", setString(func(f *File) *string { return &f.Listen.Host })},
+	{"GVID_PORT", func(f *File, v string) error {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		f.Listen.Port = port
+		return nil
+	}},
+	{"GVID_SOCKET", setString(func(f *File) *string { return &f.Listen.Socket })},
+	{"GVID_SOCKET_MODE", setString(func(f *File) *string { return &f.Listen.SocketMode })},
+	{"GVID_SOCKET_OWNER", setString(func(f *File) *string { return &f.Listen.SocketOwner })},
+	{"GVID_DISABLE_TCP", setBool(func(f *File) *bool { return &f.Listen.DisableTCP })},
+	{"GVID_TLS_CERT", setString(func(f *File) *string { return &f.TLS.Cert })},
+	{"GVID_TLS_KEY", setString(func(f *File) *string { return &f.TLS.Key })},
+	{"GVID_CLIENT_CA", setString(func(f *File) *string { return &f.TLS.ClientCA })},
+	{"GVID_CORS_ORIGINS", func(f *File, v string) error {
+		f.CORSOrigins = SplitList(v)
+		return nil
+	}},
+	{"GVID_TOKEN_FILE", setString(func(f *File) *string { return &f.Auth.TokenFile })},
+	{"GVID_WRITE_ENABLED", setBool(func(f *File) *bool { return &f.Write.Enabled })},
+	{"GVID_AUDIT_LOG", setString(func(f *File) *string { return &f.Write.AuditLog })},
+	{"GVID_DATA_DIR", func(f *File, v string) error {
+		f.DataDir = &v
+		return nil
+	}},
+	{"GVID_TOWN_REFRESH", setDuration(func(f *File) *time.Duration { return &f.Intervals.TownRefresh })},
+	{"GVID_SAMPLE_INTERVAL", setDuration(func(f *File) *time.Duration { return &f.Intervals.Sample })},
+	{"GVID_RETENTION", setDuration(func(f *File) *time.Duration { return &f.Intervals.Retention })},
+	{"GVID_SSE_HEARTBEAT", setDuration(func(f *File) *time.Duration { return &f.Intervals.SSEHeartbeat })},
+	{"GVID_SHUTDOWN_TIMEOUT", setDuration(func(f *File) *time.Duration { return &f.Intervals.ShutdownTimeout })},
+	{"GVID_LOG_LEVEL", setString(func(f *File) *string { return &f.Log.Level })},
+}
+
+// EnvNames returns the names of the supported environment overrides.
+func EnvNames() []string {
+	names := make([]string, len(envVars))
+	for i, ev := range envVars {
+		names[i] = ev.name
+	}
+	return names
+}
+
+// ApplyEnv overrides fields from GVID_* variables found by lookup,
+// usually os.LookupEnv.
+func (f *File) ApplyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for _, ev := range envVars {
+		v, ok := lookup(ev.name)
+		if !ok {
+			continue
+		}
+		if err := ev.set(f, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ev.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings without touching the filesystem.
+func (f *File) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if f.Listen.Port < 0 || f.Listen.Port > 65535 {
+		add("listen.port: %d is out of range", f.Listen.Port)
+	}
+	if f.Listen.SocketMode != "" {
+		if _, err := ParseMode(f.Listen.SocketMode); err != nil {
+			add("listen.socket_mode: %v", err)
+		}
+	}
+	if f.Listen.DisableTCP && f.Listen.Socket == "" {
+		add("listen.disable_tcp: requires listen.socket")
+	}
+	if (f.TLS.Cert == "") != (f.TLS.Key == "") {
+		add("tls: cert and key must be set together")
+	}
+	if f.TLS.ClientCA != "" && f.TLS.Cert == "" {
+		add("tls.client_ca: requires tls.cert and tls.key")
+	}
+
+	for _, origin := range f.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			add("cors_origins: %q is not an origin like http://host:port", origin)
+		}
+	}
+
+	for _, iv := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"town_refresh", f.Intervals.TownRefresh},
+		{"sample", f.Intervals.Sample},
+		{"retention", f.Intervals.Retention},
+		{"sse_heartbeat", f.Intervals.SSEHeartbeat},
+		{"shutdown_timeout", f.Intervals.ShutdownTimeout},
+	} {
+		if iv.d < 0 {
+			add("intervals.%s: must not be negative", iv.name)
+		}
+	}
+
+	if f.Log.Level != "" {
+		if _, err := logging.ParseLevel(f.Log.Level); err != nil {
+			add("log.level: %q is not debug, info, warn or error", f.Log.Level)
+		}
+	}
+
+	roles := make([]string, 0, len(f.Health))
+	for role := range f.Health {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		rule := f.Health[role]
+		if !knownRole(gastown.Role(role)) {
+			add("health.%s: unknown role", role)
+		}
+		if negative(rule.StuckAfter) || negative(rule.IdleAfter) || negative(rule.MaxCompaction) {
+			add("health.%s: thresholds must not be negative", role)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// APIConfig returns the server configuration: defaults overlaid with the
+// file's settings. It loads the token file, so it can fail on I/O as well
+// as on invalid settings.
+func (f *File) APIConfig() (api.Config, error) {
+	if err := f.Validate(); err != nil {
+		return api.Config{}, err
+	}
+
+	cfg := api.DefaultConfig()
+	setIf(&cfg.TownRoot, f.Town)
+	setIf(&cfg.Host, f.Listen.Host)
+	setIf(&cfg.Port, f.Listen.Port)
+	setIf(&cfg.Socket, f.Listen.Socket)
+	setIf(&cfg.SocketOwner, f.Listen.SocketOwner)
+	cfg.DisableTCP = f.Listen.DisableTCP
+	cfg.SocketMode = api.DefaultSocketMode
+	if f.Listen.SocketMode != "" {
+		cfg.SocketMode, _ = ParseMode(f.Listen.SocketMode)
+	}
+	cfg.TLSCert = f.TLS.Cert
+	cfg.TLSKey = f.TLS.Key
+	cfg.ClientCA = f.TLS.ClientCA
+	if f.CORSOrigins != nil {
+		cfg.CORSOrigins = f.CORSOrigins
+	}
+	cfg.WriteEnabled = f.Write.Enabled
+	cfg.AuditLog = f.Write.AuditLog
+	if f.DataDir != nil {
+		cfg.DataDir = *f.DataDir
+	}
+	setIf(&cfg.TownRefresh, f.Intervals.TownRefresh)
+	setIf(&cfg.SampleInterval, f.Intervals.Sample)
+	setIf(&cfg.Retention, f.Intervals.Retention)
+	setIf(&cfg.SSEHeartbeat, f.Intervals.SSEHeartbeat)
+	cfg.HealthRules = f.healthRules()
+
+	if f.Auth.TokenFile != "" {
+		tokens, err := api.LoadTokens(f.Auth.TokenFile)
+		if err != nil {
+			return api.Config{}, err
+		}
+		cfg.Tokens = tokens
+	}
+
+	return cfg, nil
+}
+
+// ShutdownTimeout returns the configured shutdown timeout or the default.
+func (f *File) ShutdownTimeout() time.Duration {
+	if f.Intervals.ShutdownTimeout > 0 {
+		return f.Intervals.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// LogLevel returns the configured log level, or info if unset or invalid.
+func (f *File) LogLevel() slog.Level {
+	level, err := logging.ParseLevel(f.Log.Level)
+	if err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// healthRules merges the overrides onto the built-in rules, or returns
+// nil when there are none.
+func (f *File) healthRules() gastown.HealthRules {
+	if len(f.Health) == 0 {
+		return nil
+	}
+	rules := gastown.DefaultHealthRules()
+	for role, override := range f.Health {
+		rule := rules.For(gastown.Role(role))
+		setIfPresent(&rule.StuckAfter, override.StuckAfter)
+		setIfPresent(&rule.IdleAfter, override.IdleAfter)
+		setIfPresent(&rule.MaxCompaction, override.MaxCompaction)
+		setIfPresent(&rule.StuckWithoutHook, override.StuckWithoutHook)
+		rules[gastown.Role(role)] = rule
+	}
+	return rules
+}
+
+func knownRole(role gastown.Role) bool {
+	switch role {
+	case gastown.RoleMayor, gastown.RoleDeacon, gastown.RoleWitness,
+		gastown.RoleRefinery, gastown.RoleCrew, gastown.RolePolecat:
+		return true
+	}
+	return false
+}
+
+// ParseMode parses an octal file mode such as "0660".
+func ParseMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("%q is not an octal permission such as 0660", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// setIf assigns v to *dst unless v is the zero value.
+func setIf[T comparable](dst *T, v T) {
+	var zero T
+	if v != zero {
+		*dst = v
+	}
+}
+
+// setIfPresent assigns *v to *dst when v is set, including to a zero value.
+func setIfPresent[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// negative reports whether a set threshold is below zero.
+func negative[T time.Duration | int](v *T) bool {
+	return v != nil && *v < 0
+}
+
+// SplitList splits a comma-separated list, dropping empty entries.
+func SplitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
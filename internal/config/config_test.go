@@ -0,0 +1,217 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/api"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gvid.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+workspace: /srv/project
+town: /srv/gt
+listen:
+  host: 0.0.0.0
+  port: 8080
+  socket: /run/gvid/gvid.sock
+  socket_mode: "0660"
+cors_origins: ["https://dash.example.com"]
+write:
+  enabled: true
+data_dir: ""
+intervals:
+  town_refresh: 45s
+  retention: 168h
+  shutdown_timeout: 30s
+health:
+  polecat:
+    stuck_after: 20m
+    stuck_without_hook: true
+`)
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	cfg, err := f.APIConfig()
+	if err != nil {
+		t.Fatalf("APIConfig() returned error: %v", err)
+	}
+
+	if f.Workspace != "/srv/project" || cfg.TownRoot != "/srv/gt" {
+		t.Errorf("Unexpected workspace %q or town %q", f.Workspace, cfg.TownRoot)
+	}
+	if cfg.Host != "0.0.0.0" || cfg.Port != 8080 {
+		t.Errorf("Unexpected listen address %s:%d", cfg.Host, cfg.Port)
+	}
+	if cfg.Socket != "/run/gvid/gvid.sock" || cfg.SocketMode != 0660 {
+		t.Errorf("Unexpected socket %s mode %o", cfg.Socket, cfg.SocketMode)
+	}
+	if len(cfg.CORSOrigins) != 1 || cfg.CORSOrigins[0] != "https://dash.example.com" {
+		t.Errorf("Unexpected CORS origins %v", cfg.CORSOrigins)
+	}
+	if !cfg.WriteEnabled || cfg.DataDir != "" {
+		t.Errorf("Expected write mode and in-memory data, got %v %q", cfg.WriteEnabled, cfg.DataDir)
+	}
+	if cfg.TownRefresh != 45*time.Second || f.ShutdownTimeout() != 30*time.Second {
+		t.Errorf("Unexpected intervals %s %s", cfg.TownRefresh, f.ShutdownTimeout())
+	}
+	if cfg.SampleInterval != api.DefaultSampleInterval {
+		t.Errorf("Expected default sample interval, got %s", cfg.SampleInterval)
+	}
+	if cfg.Retention != 7*24*time.Hour {
+		t.Errorf("Expected 168h retention, got %s", cfg.Retention)
+	}
+
+	polecat := cfg.HealthRules[gastown.RolePolecat]
+	defaults := gastown.DefaultHealthRules()[gastown.RolePolecat]
+	if polecat.StuckAfter != 20*time.Minute || !polecat.StuckWithoutHook || polecat.IdleAfter != defaults.IdleAfter {
+		t.Errorf("Unexpected polecat rule %+v", polecat)
+	}
+	if cfg.HealthRules[gastown.RoleWitness] != gastown.DefaultHealthRules()[gastown.RoleWitness] {
+		t.Error("Expected roles without overrides to keep their defaults")
+	}
+}
+
+func TestLoad_ZeroHealthThreshold(t *testing.T) {
+	f, err := Load(writeConfig(t, "health:\n  polecat:\n    stuck_after: 0s\n    max_compaction: 0\n"))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	cfg, err := f.APIConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	polecat := cfg.HealthRules[gastown.RolePolecat]
+	defaults := gastown.DefaultHealthRules()[gastown.RolePolecat]
+	if polecat.StuckAfter != 0 || polecat.MaxCompaction != 0 {
+		t.Errorf("Expected zero to disable the thresholds, got %+v", polecat)
+	}
+	if polecat.IdleAfter != defaults.IdleAfter || polecat.StuckWithoutHook != defaults.StuckWithoutHook {
+		t.Errorf("Expected unset fields to keep their defaults, got %+v", polecat)
+	}
+}
+
+func TestLoad_UnknownField(t *testing.T) {
+	path := writeConfig(t, "listen:\n  prot: 80\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("Expected error naming the unknown field, got %v", err)
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	f, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	cfg, err := f.APIConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != api.DefaultConfig().Port || cfg.HealthRules != nil {
+		t.Errorf("Expected defaults, got %+v", cfg)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	f := &File{Listen: Listen{Port: 8080}, Town: "/srv/gt"}
+	env := map[string]string{
+		"GVID_PORT":          "9090",
+		"GVID_CORS_ORIGINS":  "http://a.local, http://b.local,",
+		"GVID_WRITE_ENABLED": "true",
+		"GVID_SSE_HEARTBEAT": "5s",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	if err := f.ApplyEnv(lookup); err != nil {
+		t.Fatalf("ApplyEnv() returned error: %v", err)
+	}
+	if f.Listen.Port != 9090 || f.Town != "/srv/gt" {
+		t.Errorf("Unexpected port %d or town %q", f.Listen.Port, f.Town)
+	}
+	if len(f.CORSOrigins) != 2 || f.CORSOrigins[1] != "http://b.local" {
+		t.Errorf("Unexpected CORS origins %v", f.CORSOrigins)
+	}
+	if !f.Write.Enabled || f.Intervals.SSEHeartbeat != 5*time.Second {
+		t.Errorf("Unexpected write %v or heartbeat %s", f.Write.Enabled, f.Intervals.SSEHeartbeat)
+	}
+
+	env = map[string]string{"GVID_PORT": "http", "GVID_TOWN_REFRESH": "often"}
+	err := f.ApplyEnv(lookup)
+	if err == nil || !strings.Contains(err.Error(), "GVID_PORT") || !strings.Contains(err.Error(), "GVID_TOWN_REFRESH") {
+		t.Errorf("Expected errors for both variables, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	negativeMinute := -time.Minute
+
+	tests := []struct {
+		name string
+		file File
+		want string
+	}{
+		{"port", File{Listen: Listen{Port: 70000}}, "listen.port"},
+		{"socket mode", File{Listen: Listen{Socket: "/s", SocketMode: "rw"}}, "listen.socket_mode"},
+		{"no listeners", File{Listen: Listen{DisableTCP: true}}, "listen.disable_tcp"},
+		{"tls pair", File{TLS: TLS{Key: "k"}}, "cert and key"},
+		{"client ca", File{TLS: TLS{ClientCA: "ca"}}, "tls.client_ca"},
+		{"origin", File{CORSOrigins: []string{"localhost:5173"}}, "cors_origins"},
+		{"interval", File{Intervals: Intervals{Sample: -time.Second}}, "intervals.sample"},
+		{"role", File{Health: map[string]HealthRule{"cat": {}}}, "health.cat"},
+		{"threshold", File{Health: map[string]HealthRule{"crew": {IdleAfter: &negativeMinute}}}, "health.crew"},
+		{"log level", File{Log: Log{Level: "loud"}}, "log.level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.file.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	valid := File{CORSOrigins: []string{"*", "http://localhost:5173"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestAPIConfig_TokenFile(t *testing.T) {
+	tokens := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(tokens, []byte("secret read\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &File{Auth: Auth{TokenFile: tokens}}
+	cfg, err := f.APIConfig()
+	if err != nil {
+		t.Fatalf("APIConfig() returned error: %v", err)
+	}
+	if _, ok := cfg.Tokens.Lookup("secret"); !ok {
+		t.Error("Expected token from file")
+	}
+
+	f.Auth.TokenFile = filepath.Join(t.TempDir(), "missing")
+	if _, err := f.APIConfig(); err == nil {
+		t.Error("Expected error for missing token file")
+	}
+}
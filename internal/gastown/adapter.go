@@ -3,11 +3,15 @@ package gastown
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,6 +32,12 @@ type Adapter interface {
 	// Agents returns all agents across all rigs.
 	Agents(ctx context.Context) ([]Agent, error)
 
+	// Agent returns a specific agent by mail-style address.
+	Agent(ctx context.Context, address string) (*Agent, error)
+
+	// Pane captures the last lines of an agent's tmux session.
+	Pane(ctx context.Context, agent *Agent, lines int, ansi bool) (*Pane, error)
+
 	// Convoys returns active convoys.
 	Convoys(ctx context.Context) ([]Convoy, error)
 
@@ -42,11 +52,21 @@ type Adapter interface {
 
 	// Mail returns messages for an agent address.
 	Mail(ctx context.Context, address string) ([]Message, error)
+
+	// TownMail returns recent messages across every agent's inbox.
+	TownMail(ctx context.Context) ([]Message, error)
+
+	// MergeQueue returns the refinery merge queue for a rig.
+	MergeQueue(ctx context.Context, rig string) (*MergeQueue, error)
 }
 
 // FSAdapter reads Gas Town state from the filesystem and gt CLI.
 type FSAdapter struct {
 	townRoot string
+	runner   Runner
+
+	rulesMu sync.RWMutex
+	rules   HealthRules
 }
 
 // NewFSAdapter creates a new filesystem-based adapter.
@@ -54,67 +74,63 @@ func NewFSAdapter(townRoot string) *FSAdapter {
 	if townRoot == "" {
 		townRoot = filepath.Join(os.Getenv("HOME"), "gt")
 	}
-	return &FSAdapter{townRoot: townRoot}
+	return &FSAdapter{
+		townRoot: townRoot,
+		rules:    DefaultHealthRules(),
+		runner:   &DefaultRunner{},
+	}
+}
+
+// NewFSAdapterWithRunner creates an adapter with a custom runner (for testing).
+func NewFSAdapterWithRunner(townRoot string, runner Runner) *FSAdapter {
+	a := NewFSAdapter(townRoot)
+	a.runner = runner
+	return a
+}
+
+// SetHealthRules replaces the rules used to classify agent status.
+// Roles missing from rules fall back to DefaultHealthRules.
+func (a *FSAdapter) SetHealthRules(rules HealthRules) {
+	a.rulesMu.Lock()
+	defer a.rulesMu.Unlock()
+	a.rules = rules
+}
+
+// healthRules returns the current status classification rules.
+func (a *FSAdapter) healthRules() HealthRules {
+	a.rulesMu.RLock()
+	defer a.rulesMu.RUnlock()
+	return a.rules
 }
 
 // Status returns the overall town health status.
 func (a *FSAdapter) Status(ctx context.Context) (*TownStatus, error) {
-	status := &TownStatus{
-		TownRoot: a.townRoot,
-	}
-
 	// Check if town exists
 	if !a.townExists() {
-		status.Healthy = false
-		status.Error = fmt.Sprintf("Town not found at %s", a.townRoot)
-		return status, nil
+		return newTownStatus(a.townRoot, nil, fmt.Errorf("Town not found at %s", a.townRoot)), nil
 	}
 
-	// Get town data
 	town, err := a.Town(ctx)
+	return newTownStatus(a.townRoot, town, err), nil
+}
+
+// newTownStatus summarizes a scanned town. A non-nil err marks the town unhealthy.
+func newTownStatus(townRoot string, town *Town, err error) *TownStatus {
+	status := &TownStatus{
+		TownRoot: townRoot,
+	}
+
 	if err != nil {
 		status.Healthy = false
 		status.Error = err.Error()
-		return status, nil
+		return status
 	}
 
 	// Count agents
 	status.ActiveRigs = len(town.Rigs)
-	for _, rig := range town.Rigs {
-		status.TotalAgents += len(rig.Polecats) + len(rig.Crew)
-		if rig.Witness != nil {
-			status.TotalAgents++
-			if rig.Witness.Status == StatusActive {
-				status.ActiveAgents++
-			}
-		}
-		if rig.Refinery != nil {
-			status.TotalAgents++
-			if rig.Refinery.Status == StatusActive {
-				status.ActiveAgents++
-			}
-		}
-		for _, p := range rig.Polecats {
-			if p.Status == StatusActive {
-				status.ActiveAgents++
-			}
-		}
-		for _, c := range rig.Crew {
-			if c.Status == StatusActive {
-				status.ActiveAgents++
-			}
-		}
-	}
-
-	if town.Mayor != nil {
-		status.TotalAgents++
-		if town.Mayor.Status == StatusActive {
-			status.ActiveAgents++
-		}
-	}
-	if town.Deacon != nil {
+	for _, agent := range townAgents(town) {
 		status.TotalAgents++
-		if town.Deacon.Status == StatusActive {
+		if agent.Status == StatusActive {
 			status.ActiveAgents++
 		}
 	}
@@ -122,13 +138,29 @@ func (a *FSAdapter) Status(ctx context.Context) (*TownStatus, error) {
 	status.OpenConvoys = len(town.Convoys)
 	status.Healthy = true
 
-	return status, nil
+	return status
 }
 
-// Town returns the full town structure.
+// Town returns the full town structure. A town whose convoys cannot be
+// listed is still returned, without convoys.
 func (a *FSAdapter) Town(ctx context.Context) (*Town, error) {
+	scan, err := a.scanTown(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return scan.town, nil
+}
+
+// townScan is the result of scanning the town.
+type townScan struct {
+	town       *Town
+	convoysErr error // Set when convoys could not be listed; the scan still succeeds
+}
+
+// scanTown reads the full town structure.
+func (a *FSAdapter) scanTown(ctx context.Context) (*townScan, error) {
 	if !a.townExists() {
-		return nil, fmt.Errorf("town not found at %s", a.townRoot)
+		return nil, &TownNotFoundError{Root: a.townRoot}
 	}
 
 	town := &Town{
@@ -142,7 +174,7 @@ func (a *FSAdapter) Town(ctx context.Context) (*Town, error) {
 	}
 
 	// Get tmux sessions to determine agent status
-	sessions := a.getTmuxSessions()
+	sessions := a.getTmuxSessions(ctx)
 
 	// Check mayor
 	if a.dirExists(filepath.Join(a.townRoot, "mayor")) {
@@ -155,7 +187,7 @@ func (a *FSAdapter) Town(ctx context.Context) (*Town, error) {
 	}
 
 	// Check deacon (via daemon)
-	if a.daemonRunning() {
+	if a.daemonRunning(ctx) {
 		deacon := &Agent{
 			Role:   RoleDeacon,
 			Name:   "deacon",
@@ -166,32 +198,38 @@ func (a *FSAdapter) Town(ctx context.Context) (*Town, error) {
 	}
 
 	// Find rigs
-	rigs, err := a.Rigs(ctx)
+	rigs, err := a.scanRigs(ctx, sessions)
 	if err == nil {
 		town.Rigs = rigs
 	}
 
 	// Get convoys
-	convoys, err := a.Convoys(ctx)
-	if err == nil {
+	convoys, convoysErr := a.Convoys(ctx)
+	if convoysErr == nil {
 		town.Convoys = convoys
 	}
 
-	return town, nil
+	return &townScan{town: town, convoysErr: convoysErr}, nil
 }
 
 // Rigs returns all rigs in the town.
 func (a *FSAdapter) Rigs(ctx context.Context) ([]Rig, error) {
+	return a.scanRigs(ctx, a.getTmuxSessions(ctx))
+}
+
+// scanRigs finds rigs and their agents, using sessions to classify status.
+func (a *FSAdapter) scanRigs(ctx context.Context, sessions map[string]time.Time) ([]Rig, error) {
 	var rigs []Rig
 
 	// Look for directories that have rig markers
 	entries, err := os.ReadDir(a.townRoot)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &TownNotFoundError{Root: a.townRoot}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	sessions := a.getTmuxSessions()
-
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -252,6 +290,7 @@ func (a *FSAdapter) Rigs(ctx context.Context) ([]Rig, error) {
 							Rig:  name,
 						}
 						a.enrichAgent(&polecat, sessions)
+						polecat.Git = a.gitStatus(ctx, polecat.WorkDir)
 						rig.Polecats = append(rig.Polecats, polecat)
 					}
 				}
@@ -271,6 +310,7 @@ func (a *FSAdapter) Rigs(ctx context.Context) ([]Rig, error) {
 							Rig:  name,
 						}
 						a.enrichAgent(&crew, sessions)
+						crew.Git = a.gitStatus(ctx, crew.WorkDir)
 						rig.Crew = append(rig.Crew, crew)
 					}
 				}
@@ -296,7 +336,7 @@ func (a *FSAdapter) Rig(ctx context.Context, name string) (*Rig, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("rig not found: %s", name)
+	return nil, &RigNotFoundError{Name: name}
 }
 
 // Agents returns all agents across all rigs.
@@ -306,6 +346,11 @@ func (a *FSAdapter) Agents(ctx context.Context) ([]Agent, error) {
 		return nil, err
 	}
 
+	return townAgents(town), nil
+}
+
+// townAgents flattens all agents of a town into a single list.
+func townAgents(town *Town) []Agent {
 	var agents []Agent
 
 	if town.Mayor != nil {
@@ -326,17 +371,37 @@ func (a *FSAdapter) Agents(ctx context.Context) ([]Agent, error) {
 		agents = append(agents, rig.Crew...)
 	}
 
-	return agents, nil
+	return agents
+}
+
+// Agent returns a specific agent by mail-style address.
+func (a *FSAdapter) Agent(ctx context.Context, address string) (*Agent, error) {
+	agents, err := a.Agents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, agent := range agents {
+		if agent.Address() == address {
+			return &agent, nil
+		}
+	}
+
+	return nil, &NotFoundError{Kind: "agent", ID: address}
 }
 
 // Convoys returns active convoys by running gt convoy list.
 func (a *FSAdapter) Convoys(ctx context.Context) ([]Convoy, error) {
 	// Try to run gt convoy list --json
-	cmd := exec.CommandContext(ctx, "gt", "convoy", "list", "--json")
-	cmd.Dir = a.townRoot
-	output, err := cmd.Output()
+	output, err := a.runner.Run(ctx, Command{
+		Name: "gt",
+		Args: []string{"convoy", "list", "--json"},
+		Dir:  a.townRoot,
+	})
 	if err != nil {
-		// gt might not be installed or convoy command might fail
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(output))) == 0 {
 		return nil, nil
 	}
 
@@ -378,7 +443,7 @@ func (a *FSAdapter) Convoys(ctx context.Context) ([]Convoy, error) {
 			Agents      []string `json:"agents,omitempty"`
 		}
 		if err := json.Unmarshal(output, &raw); err != nil {
-			return nil, nil
+			return nil, &ParseError{Command: "convoy list", Err: err}
 		}
 		rawConvoys = append(rawConvoys, raw)
 	}
@@ -407,7 +472,7 @@ func (a *FSAdapter) Convoy(ctx context.Context, id string) (*Convoy, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("convoy not found: %s", id)
+	return nil, &NotFoundError{Kind: "convoy", ID: id}
 }
 
 // parseRawConvoy converts raw convoy data to a Convoy struct.
@@ -470,22 +535,86 @@ func (a *FSAdapter) parseRawConvoy(id, title, status, priority, rig string,
 // Mail returns messages for an agent address.
 func (a *FSAdapter) Mail(ctx context.Context, address string) ([]Message, error) {
 	// Run gt mail inbox for the address
-	cmd := exec.CommandContext(ctx, "gt", "mail", "inbox", "--json")
-	cmd.Dir = a.townRoot
-	cmd.Env = append(os.Environ(), fmt.Sprintf("GT_ROLE=%s", address))
-	output, err := cmd.Output()
+	output, err := a.runner.Run(ctx, Command{
+		Name: "gt",
+		Args: []string{"mail", "inbox", "--json"},
+		Dir:  a.townRoot,
+		Env:  []string{fmt.Sprintf("GT_ROLE=%s", address)},
+	})
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
-	var messages []Message
+	messages := []Message{}
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return messages, nil
+	}
 	if err := json.Unmarshal(output, &messages); err != nil {
-		return nil, nil
+		return nil, &ParseError{Command: "mail inbox", Err: err}
 	}
 
 	return messages, nil
 }
 
+// TownMail returns the messages in every agent's inbox, newest first.
+func (a *FSAdapter) TownMail(ctx context.Context) ([]Message, error) {
+	agents, err := a.Agents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages, _, err := a.agentMail(ctx, agents)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// agentMail reads the inboxes of the given agents, dropping duplicates of
+// messages delivered to several recipients. It also returns the number of
+// unread messages in each agent's inbox, counted before deduplication.
+// An inbox that cannot be read is left out of the counts and the first
+// such error is returned with what the other inboxes held.
+func (a *FSAdapter) agentMail(ctx context.Context, agents []Agent) ([]Message, map[string]int, error) {
+	messages := []Message{}
+	unread := make(map[string]int)
+	seen := make(map[string]bool)
+	var firstErr error
+
+	for _, agent := range agents {
+		inbox, err := a.Mail(ctx, agent.Address())
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			// No other inbox can be read without gt or after cancellation
+			if IsGTNotFoundError(err) || ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		unread[agent.Address()] = 0
+		for _, msg := range inbox {
+			if !msg.Read {
+				unread[agent.Address()]++
+			}
+			if msg.ID != "" && seen[msg.ID] {
+				continue
+			}
+			seen[msg.ID] = true
+			if msg.To == "" {
+				msg.To = agent.Address()
+			}
+			messages = append(messages, msg)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.After(messages[j].Timestamp)
+	})
+
+	return messages, unread, firstErr
+}
+
 // Helper methods
 
 func (a *FSAdapter) townExists() bool {
@@ -512,26 +641,36 @@ func (a *FSAdapter) readTownConfig() (*TownConfig, error) {
 	return &config, nil
 }
 
-func (a *FSAdapter) getTmuxSessions() map[string]bool {
-	sessions := make(map[string]bool)
+// getTmuxSessions returns running tmux sessions mapped to their last activity.
+func (a *FSAdapter) getTmuxSessions(ctx context.Context) map[string]time.Time {
+	sessions := make(map[string]time.Time)
 
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-	output, err := cmd.Output()
+	output, err := a.runner.Run(ctx, Command{
+		Name: "tmux",
+		Args: []string{"list-sessions", "-F", "#{session_name} #{session_activity}"},
+	})
 	if err != nil {
 		return sessions
 	}
 
 	for _, line := range strings.Split(string(output), "\n") {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			sessions[line] = true
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
 		}
+		var activity time.Time
+		if len(fields) > 1 {
+			if secs, err := strconv.ParseInt(fields[1], 10, 64); err == nil && secs > 0 {
+				activity = time.Unix(secs, 0)
+			}
+		}
+		sessions[fields[0]] = activity
 	}
 
 	return sessions
 }
 
-func (a *FSAdapter) daemonRunning() bool {
+func (a *FSAdapter) daemonRunning(ctx context.Context) bool {
 	// Check if gt daemon is running by looking for pid file or process
 	pidFile := filepath.Join(a.townRoot, "mayor", "daemon.pid")
 	if _, err := os.Stat(pidFile); err == nil {
@@ -539,13 +678,15 @@ func (a *FSAdapter) daemonRunning() bool {
 	}
 
 	// Also check via gt daemon status
-	cmd := exec.Command("gt", "daemon", "status")
-	cmd.Dir = a.townRoot
-	err := cmd.Run()
+	_, err := a.runner.Run(ctx, Command{
+		Name: "gt",
+		Args: []string{"daemon", "status"},
+		Dir:  a.townRoot,
+	})
 	return err == nil
 }
 
-// LastActivity returns the last modification time of agent's workspace.
+// LastActivity returns the newest file modification in an agent's workspace.
 func (a *FSAdapter) LastActivity(rigName, agentName string) time.Time {
 	var checkPath string
 	if agentName == "witness" {
@@ -559,11 +700,7 @@ func (a *FSAdapter) LastActivity(rigName, agentName string) time.Time {
 		}
 	}
 
-	info, err := os.Stat(checkPath)
-	if err != nil {
-		return time.Time{}
-	}
-	return info.ModTime()
+	return latestModTime(checkPath)
 }
 
 // getAgentWorkDir returns the working directory for an agent.
@@ -586,8 +723,9 @@ func (a *FSAdapter) getAgentWorkDir(rigName string, role Role, name string) stri
 	}
 }
 
-// enrichAgent adds session, molecule, and hook info to an agent.
-func (a *FSAdapter) enrichAgent(agent *Agent, sessions map[string]bool) {
+// enrichAgent adds session, molecule, and hook info to an agent and classifies
+// its status using the health rule for its role.
+func (a *FSAdapter) enrichAgent(agent *Agent, sessions map[string]time.Time) {
 	workDir := a.getAgentWorkDir(agent.Rig, agent.Role, agent.Name)
 	if workDir == "" {
 		return
@@ -598,15 +736,15 @@ func (a *FSAdapter) enrichAgent(agent *Agent, sessions map[string]bool) {
 	sessionName := a.getSessionName(agent)
 	agent.Session = sessionName
 
-	// Check if session is active
-	if sessions[sessionName] {
-		agent.Status = StatusActive
-	} else {
-		agent.Status = StatusOffline
+	paneActivity, running := sessions[sessionName]
+	signals := ActivitySignals{
+		SessionRunning: running,
+		PaneActivity:   paneActivity,
 	}
 
 	// Read seance file for compaction level
 	seancePath := filepath.Join(workDir, ".claude", "seance.json")
+	signals.SeanceChange = fileModTime(seancePath)
 	if data, err := os.ReadFile(seancePath); err == nil {
 		var seance struct {
 			Compaction int    `json:"compaction"`
@@ -622,6 +760,7 @@ func (a *FSAdapter) enrichAgent(agent *Agent, sessions map[string]bool) {
 
 	// Check hook for attached molecule
 	hookPath := filepath.Join(workDir, ".claude", "hook.json")
+	signals.HookChange = fileModTime(hookPath)
 	if data, err := os.ReadFile(hookPath); err == nil {
 		var hook struct {
 			Molecule string `json:"molecule,omitempty"`
@@ -648,31 +787,33 @@ func (a *FSAdapter) enrichAgent(agent *Agent, sessions map[string]bool) {
 		}
 	}
 
-	// Get last activity time
-	if info, err := os.Stat(workDir); err == nil {
-		agent.LastActive = info.ModTime()
+	// Only scan the worktree when a session is running; offline agents are
+	// classified without it.
+	if running {
+		signals.WorktreeChange = latestModTime(workDir)
 	}
+	signals.HookAttached = agent.HookAttached
+	signals.Compaction = agent.Compaction
 
-	// Detect stuck status: active session but no activity for 10+ minutes
-	if agent.Status == StatusActive && !agent.LastActive.IsZero() {
-		if time.Since(agent.LastActive) > 10*time.Minute {
-			agent.Status = StatusStuck
-		} else if time.Since(agent.LastActive) > 2*time.Minute && !agent.HookAttached {
-			agent.Status = StatusIdle
-		}
-	}
+	agent.LastActive, agent.ActivitySource = signals.LastActivity()
+	agent.Status, agent.StatusReason = a.healthRules().For(agent.Role).Classify(signals, time.Now())
 }
 
 // Molecules returns all active molecules across all agents.
 func (a *FSAdapter) Molecules(ctx context.Context) ([]Molecule, error) {
-	var molecules []Molecule
-
 	// Collect all agent work directories
 	agents, err := a.Agents(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	return a.agentMolecules(agents), nil
+}
+
+// agentMolecules reads the molecules attached to the given agents.
+func (a *FSAdapter) agentMolecules(agents []Agent) []Molecule {
+	var molecules []Molecule
+
 	seen := make(map[string]bool)
 
 	for _, agent := range agents {
@@ -700,7 +841,7 @@ func (a *FSAdapter) Molecules(ctx context.Context) ([]Molecule, error) {
 		molecules = append(molecules, *mol)
 	}
 
-	return molecules, nil
+	return molecules
 }
 
 // Molecule returns a specific molecule by ID.
@@ -716,7 +857,7 @@ func (a *FSAdapter) Molecule(ctx context.Context, id string) (*Molecule, error)
 		}
 	}
 
-	return nil, fmt.Errorf("molecule not found: %s", id)
+	return nil, &NotFoundError{Kind: "molecule", ID: id}
 }
 
 // parseMoleculeFile reads and parses a molecule.json file.
@@ -789,6 +930,8 @@ func (a *FSAdapter) parseMoleculeFile(path string) (*Molecule, error) {
 		})
 	}
 
+	ComputeStepTimings(mol)
+
 	// Calculate progress
 	mol.Total = len(mol.Steps)
 	for _, step := range mol.Steps {
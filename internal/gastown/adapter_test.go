@@ -2,9 +2,12 @@ package gastown
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestFSAdapter_Status_NoTown(t *testing.T) {
@@ -120,3 +123,554 @@ func TestNewFSAdapter_DefaultPath(t *testing.T) {
 		t.Errorf("Expected default path %s, got %s", expected, status.TownRoot)
 	}
 }
+
+// newTestTown creates a minimal town with one rig and one polecat.
+func newTestTown(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	for _, dir := range []string{
+		filepath.Join(tmpDir, "mayor"),
+		filepath.Join(tmpDir, "gastown", "witness"),
+		filepath.Join(tmpDir, "gastown", "polecats", "nux"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "mayor", "town.json"), []byte(`{"name":"test-town"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return tmpDir
+}
+
+func TestFSAdapter_Agents_SessionStatus(t *testing.T) {
+	townRoot := newTestTown(t)
+
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte("gt-gastown-nux 0\ngt-mayor 0\n"))
+	mock.SetError("gt daemon status", fmt.Errorf("not running"))
+
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	agent, err := adapter.Agent(context.Background(), "gastown/nux")
+	if err != nil {
+		t.Fatalf("Agent() returned error: %v", err)
+	}
+	if agent.Session != "gt-gastown-nux" {
+		t.Errorf("Expected session gt-gastown-nux, got %s", agent.Session)
+	}
+	if agent.Status == StatusOffline {
+		t.Errorf("Expected running session, got status %s (%s)", agent.Status, agent.StatusReason)
+	}
+
+	witness, err := adapter.Agent(context.Background(), "gastown/witness")
+	if err != nil {
+		t.Fatalf("Agent() returned error: %v", err)
+	}
+	if witness.Status != StatusOffline {
+		t.Errorf("Expected offline witness, got %s", witness.Status)
+	}
+}
+
+func TestFSAdapter_Convoys(t *testing.T) {
+	townRoot := newTestTown(t)
+
+	mock := NewMockRunner()
+	mock.SetResponse("gt convoy list --json", []byte(`[
+		{"id": "hq-cv-1", "title": "Auth", "status": "in_progress", "issues": ["a", "b", "c", "d"], "completed": 1}
+	]`))
+
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	convoys, err := adapter.Convoys(context.Background())
+	if err != nil {
+		t.Fatalf("Convoys() returned error: %v", err)
+	}
+	if len(convoys) != 1 {
+		t.Fatalf("Expected 1 convoy, got %d", len(convoys))
+	}
+
+	c := convoys[0]
+	if c.Status != ConvoyStatusInProgress {
+		t.Errorf("Expected status in_progress, got %s", c.Status)
+	}
+	if c.Total != 4 || c.Progress != 25 {
+		t.Errorf("Expected total=4 progress=25, got total=%d progress=%d", c.Total, c.Progress)
+	}
+
+	if len(mock.Calls) != 1 || mock.Calls[0].Dir != townRoot {
+		t.Errorf("Expected gt to run in town root, got %+v", mock.Calls)
+	}
+}
+
+func TestFSAdapter_Mail(t *testing.T) {
+	townRoot := newTestTown(t)
+
+	mock := NewMockRunner()
+	mock.SetResponse("gt mail inbox --json", []byte(`[
+		{"id": "msg-1", "from": "mayor/", "to": "gastown/nux", "subject": "Hi", "read": false}
+	]`))
+
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	messages, err := adapter.Mail(context.Background(), "gastown/nux")
+	if err != nil {
+		t.Fatalf("Mail() returned error: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ID != "msg-1" {
+		t.Fatalf("Unexpected messages: %+v", messages)
+	}
+
+	env := mock.Calls[0].Env
+	if len(env) != 1 || env[0] != "GT_ROLE=gastown/nux" {
+		t.Errorf("Expected GT_ROLE env, got %v", env)
+	}
+}
+
+func TestFSAdapter_Mail_Errors(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetError("gt mail inbox", fmt.Errorf("gt: mailbox locked"))
+
+	adapter := NewFSAdapterWithRunner(newTestTown(t), mock)
+	if _, err := adapter.Mail(context.Background(), "gastown/nux"); err == nil {
+		t.Error("Expected gt failure to be returned")
+	}
+
+	delete(mock.Errors, "gt mail inbox")
+	mock.SetResponse("gt mail inbox", []byte("not json"))
+	if _, err := adapter.Mail(context.Background(), "gastown/nux"); err == nil {
+		t.Error("Expected parse failure to be returned")
+	}
+}
+
+func TestFSAdapter_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	townRoot := newTestTown(t)
+
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte(""))
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	if _, err := NewFSAdapterWithRunner("/tmp/nonexistent-gastown-test", mock).Rigs(ctx); !IsTownNotFoundError(err) {
+		t.Errorf("Rigs() without a town: expected TownNotFoundError, got %v", err)
+	}
+	if _, err := adapter.Rig(ctx, "beads"); !IsRigNotFoundError(err) {
+		t.Errorf("Rig(): expected RigNotFoundError, got %v", err)
+	}
+	if _, err := adapter.Agent(ctx, "gastown/slit"); !IsNotFoundError(err) {
+		t.Errorf("Agent(): expected NotFoundError, got %v", err)
+	}
+
+	// gt failures are no longer reported as an empty convoy list
+	mock.SetError("gt convoy list", &GTExecutionError{Command: "gt convoy list --json", Err: fmt.Errorf("exit status 1")})
+	if _, err := adapter.Convoys(ctx); !IsGTExecutionError(err) {
+		t.Errorf("Convoys(): expected GTExecutionError, got %v", err)
+	}
+	if _, err := adapter.Town(ctx); err != nil {
+		t.Errorf("Town() should tolerate a convoy failure, got %v", err)
+	}
+
+	delete(mock.Errors, "gt convoy list")
+	mock.SetResponse("gt convoy list", []byte("not json"))
+	if _, err := adapter.Convoys(ctx); !IsParseError(err) {
+		t.Errorf("Convoys(): expected ParseError, got %v", err)
+	}
+
+	mock.SetResponse("gt convoy list", []byte(""))
+	convoys, err := adapter.Convoys(ctx)
+	if err != nil || len(convoys) != 0 {
+		t.Errorf("Convoys() with no output: expected no convoys, got %v, %v", convoys, err)
+	}
+	if _, err := adapter.Convoy(ctx, "hq-cv-1"); !IsNotFoundError(err) {
+		t.Errorf("Convoy(): expected NotFoundError, got %v", err)
+	}
+
+	mock.SetResponse("gt mail inbox", []byte("not json"))
+	if _, err := adapter.Mail(ctx, "gastown/nux"); !IsParseError(err) {
+		t.Errorf("Mail(): expected ParseError, got %v", err)
+	}
+}
+
+func TestCachedAdapter_ConvoysError(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte(""))
+	mock.SetResponse("gt mail inbox", []byte("[]"))
+	mock.SetError("gt convoy list", &GTNotFoundError{})
+
+	cache := NewCachedAdapter(NewFSAdapterWithRunner(newTestTown(t), mock), time.Hour)
+	ctx := context.Background()
+
+	if _, err := cache.Town(ctx); err != nil {
+		t.Fatalf("Town() returned error: %v", err)
+	}
+	if _, err := cache.Convoys(ctx); !IsGTNotFoundError(err) {
+		t.Errorf("Convoys(): expected GTNotFoundError, got %v", err)
+	}
+	if _, err := cache.Convoy(ctx, "hq-cv-1"); !IsGTNotFoundError(err) {
+		t.Errorf("Convoy(): expected GTNotFoundError, got %v", err)
+	}
+}
+
+func TestDefaultRunner_Errors(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	runner := &DefaultRunner{}
+	ctx := context.Background()
+
+	if _, err := runner.Run(ctx, Command{Name: "gt", Args: []string{"convoy", "list"}}); !IsGTNotFoundError(err) {
+		t.Errorf("Expected GTNotFoundError for a missing gt, got %v", err)
+	}
+
+	var notFound *CommandNotFoundError
+	if _, err := runner.Run(ctx, Command{Name: "tmux", Args: []string{"list-sessions"}}); !errors.As(err, &notFound) || notFound.Name != "tmux" {
+		t.Errorf("Expected CommandNotFoundError for a missing tmux, got %v", err)
+	}
+
+	exit := errors.New("exit status 1")
+	if err := commandError(Command{Name: "gt", Args: []string{"convoy", "list"}}, "boom", exit); !IsGTExecutionError(err) {
+		t.Errorf("Expected GTExecutionError for a failing gt, got %v", err)
+	}
+	var cmdErr *CommandError
+	if err := commandError(Command{Name: "git", Args: []string{"merge-base"}}, "", exit); !errors.As(err, &cmdErr) {
+		t.Errorf("Expected CommandError for a failing git, got %v", err)
+	} else if cmdErr.Name != "git" || cmdErr.Command != "git merge-base" {
+		t.Errorf("Expected git command in error, got %+v", cmdErr)
+	}
+}
+
+func TestCachedAdapter_UnreadMail(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte(""))
+	mock.SetResponse("gt mail inbox --json", []byte(`[
+		{"id": "msg-1", "from": "mayor/", "subject": "Hi", "read": false},
+		{"id": "msg-2", "from": "mayor/", "subject": "Old", "read": true}
+	]`))
+
+	cache := NewCachedAdapter(NewFSAdapterWithRunner(newTestTown(t), mock), time.Minute)
+	ctx := context.Background()
+
+	agents, err := cache.Agents(ctx)
+	if err != nil {
+		t.Fatalf("Agents() returned error: %v", err)
+	}
+	for _, agent := range agents {
+		if agent.UnreadMail != 1 {
+			t.Errorf("Expected 1 unread for %s, got %d", agent.Address(), agent.UnreadMail)
+		}
+	}
+
+	// The same messages in every inbox appear once in the town feed
+	mail, err := cache.TownMail(ctx)
+	if err != nil {
+		t.Fatalf("TownMail() returned error: %v", err)
+	}
+	if len(mail) != 2 {
+		t.Errorf("Expected 2 deduplicated messages, got %d", len(mail))
+	}
+}
+
+// inboxFailRunner fails gt mail inbox for one address.
+type inboxFailRunner struct {
+	*MockRunner
+	address string
+}
+
+func (r *inboxFailRunner) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	if len(cmd.Env) == 1 && cmd.Env[0] == "GT_ROLE="+r.address {
+		return nil, &GTExecutionError{Command: cmd.String(), Err: fmt.Errorf("exit status 1")}
+	}
+	return r.MockRunner.Run(ctx, cmd)
+}
+
+func TestCachedAdapter_UnreadMailPartialFailure(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte(""))
+	mock.SetResponse("gt mail inbox --json", []byte(`[{"id": "msg-1", "subject": "Hi", "read": false}]`))
+	runner := &inboxFailRunner{MockRunner: mock, address: "gastown/nux"}
+
+	cache := NewCachedAdapter(NewFSAdapterWithRunner(newTestTown(t), runner), time.Minute)
+	ctx := context.Background()
+
+	agents, err := cache.Agents(ctx)
+	if err != nil {
+		t.Fatalf("Agents() returned error: %v", err)
+	}
+	for _, agent := range agents {
+		if agent.Address() == "gastown/nux" {
+			if !agent.MailUnknown || agent.UnreadMail != 0 {
+				t.Errorf("Expected unknown unread count for %s, got %+v", agent.Address(), agent)
+			}
+		} else if agent.MailUnknown || agent.UnreadMail != 1 {
+			t.Errorf("Expected 1 unread for %s, got %d (unknown %v)", agent.Address(), agent.UnreadMail, agent.MailUnknown)
+		}
+	}
+
+	// The town feed is incomplete, so it still reports the failure
+	if _, err := cache.TownMail(ctx); !IsGTExecutionError(err) {
+		t.Errorf("TownMail(): expected GTExecutionError, got %v", err)
+	}
+}
+
+func TestFSAdapter_Pane(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetResponse("tmux capture-pane", []byte("line 1\nline 2\nline 3\n\n\n"))
+
+	adapter := NewFSAdapterWithRunner(t.TempDir(), mock)
+
+	agent := &Agent{Role: RolePolecat, Name: "nux", Rig: "gastown"}
+	pane, err := adapter.Pane(context.Background(), agent, 2, true)
+	if err != nil {
+		t.Fatalf("Pane() returned error: %v", err)
+	}
+
+	if pane.Session != "gt-gastown-nux" {
+		t.Errorf("Expected session gt-gastown-nux, got %s", pane.Session)
+	}
+	if pane.Content() != "line 2\nline 3" {
+		t.Errorf("Unexpected pane content: %q", pane.Content())
+	}
+	if !mock.Called("tmux capture-pane -p -J -t =gt-gastown-nux: -S -2 -e") {
+		t.Errorf("Unexpected tmux invocation: %+v", mock.Calls)
+	}
+}
+
+func TestFSAdapter_PropagatesContext(t *testing.T) {
+	townRoot := newTestTown(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runner := &ctxRecordingRunner{}
+	adapter := NewFSAdapterWithRunner(townRoot, runner)
+
+	if _, err := adapter.Town(ctx); err != nil {
+		t.Fatalf("Town() returned error: %v", err)
+	}
+	if runner.calls == 0 {
+		t.Fatal("Expected commands to be run")
+	}
+	if runner.withoutCancel > 0 {
+		t.Errorf("%d commands ran without the caller's context", runner.withoutCancel)
+	}
+}
+
+// ctxRecordingRunner counts commands run with a context that was not canceled.
+type ctxRecordingRunner struct {
+	calls         int
+	withoutCancel int
+}
+
+func (r *ctxRecordingRunner) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	r.calls++
+	if ctx.Err() == nil {
+		r.withoutCancel++
+	}
+	return nil, ctx.Err()
+}
+
+func TestCachedAdapter_ServesSnapshot(t *testing.T) {
+	townRoot := newTestTown(t)
+
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte("gt-gastown-nux 0\n"))
+	mock.SetResponse("gt convoy list --json", []byte(`[]`))
+
+	cache := NewCachedAdapter(NewFSAdapterWithRunner(townRoot, mock), time.Hour)
+	ctx := context.Background()
+
+	if _, err := cache.Status(ctx); err != nil {
+		t.Fatalf("Status() returned error: %v", err)
+	}
+	scans := len(mock.Calls)
+
+	if _, err := cache.Agents(ctx); err != nil {
+		t.Fatalf("Agents() returned error: %v", err)
+	}
+	if _, err := cache.Molecules(ctx); err != nil {
+		t.Fatalf("Molecules() returned error: %v", err)
+	}
+	if _, err := cache.Convoys(ctx); err != nil {
+		t.Fatalf("Convoys() returned error: %v", err)
+	}
+	if len(mock.Calls) != scans {
+		t.Errorf("Expected reads to be served from the snapshot, got %d extra commands", len(mock.Calls)-scans)
+	}
+	if cache.SnapshotTime().IsZero() {
+		t.Error("Expected snapshot time to be set")
+	}
+
+	// A new polecat shows up after an explicit refresh
+	if err := os.MkdirAll(filepath.Join(townRoot, "gastown", "polecats", "slit"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() returned error: %v", err)
+	}
+	if _, err := cache.Agent(ctx, "gastown/slit"); err != nil {
+		t.Errorf("Expected new polecat after refresh: %v", err)
+	}
+}
+
+func TestCachedAdapter_NoTown(t *testing.T) {
+	cache := NewCachedAdapter(NewFSAdapterWithRunner("/tmp/nonexistent-gastown-test", NewMockRunner()), time.Hour)
+
+	status, err := cache.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() returned error: %v", err)
+	}
+	if status.Healthy {
+		t.Error("Expected Healthy=false for non-existent town")
+	}
+
+	if _, err := cache.Agents(context.Background()); err == nil {
+		t.Error("Expected error for non-existent town")
+	}
+}
+
+func TestFSAdapter_FingerprintChanges(t *testing.T) {
+	townRoot := newTestTown(t)
+	adapter := NewFSAdapterWithRunner(townRoot, NewMockRunner())
+
+	before := adapter.fingerprint()
+
+	hookDir := filepath.Join(townRoot, "gastown", "polecats", "nux", ".claude")
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hookDir, "hook.json"), []byte(`{"attached":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if adapter.fingerprint() == before {
+		t.Error("Expected fingerprint to change after hook.json was written")
+	}
+}
+
+func TestFSAdapter_SendMail(t *testing.T) {
+	townRoot := newTestTown(t)
+	mock := NewMockRunner()
+	mock.SetResponse("gt mail send", []byte("sent\n"))
+
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	err := adapter.SendMail(context.Background(), Message{
+		From:     "overseer",
+		To:       "gastown/nux",
+		Subject:  "Status?",
+		Body:     "You look stuck",
+		Priority: "high",
+	})
+	if err != nil {
+		t.Fatalf("SendMail() returned error: %v", err)
+	}
+
+	call := mock.Calls[0]
+	want := "gt mail send gastown/nux -s Status? -m You look stuck --priority high"
+	if call.String() != want {
+		t.Errorf("Expected %q, got %q", want, call.String())
+	}
+	if len(call.Env) != 1 || call.Env[0] != "GT_ROLE=overseer" {
+		t.Errorf("Expected sender GT_ROLE env, got %v", call.Env)
+	}
+
+	if err := adapter.SendMail(context.Background(), Message{To: "gastown/nux"}); err == nil {
+		t.Error("Expected error for missing subject")
+	}
+}
+
+func TestFSAdapter_Nudge(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetResponse("tmux send-keys", nil)
+
+	adapter := NewFSAdapterWithRunner(t.TempDir(), mock)
+	agent := &Agent{Role: RolePolecat, Name: "nux", Rig: "gastown", Session: "gt-gastown-nux"}
+
+	if err := adapter.Nudge(context.Background(), agent, "check your hook"); err != nil {
+		t.Fatalf("Nudge() returned error: %v", err)
+	}
+
+	if len(mock.Calls) != 2 {
+		t.Fatalf("Expected 2 tmux calls, got %d", len(mock.Calls))
+	}
+	if got := mock.Calls[0].String(); got != "tmux send-keys -t =gt-gastown-nux: -l check your hook" {
+		t.Errorf("Unexpected literal send: %s", got)
+	}
+	if got := mock.Calls[1].String(); got != "tmux send-keys -t =gt-gastown-nux: Enter" {
+		t.Errorf("Unexpected submit: %s", got)
+	}
+}
+
+func TestFSAdapter_AgentLifecycle(t *testing.T) {
+	townRoot := newTestTown(t)
+	agent := &Agent{Role: RolePolecat, Name: "nux", Rig: "gastown"}
+	ctx := context.Background()
+
+	// Session not running: start spawns it, stop is a no-op
+	mock := NewMockRunner()
+	mock.SetError("tmux has-session", fmt.Errorf("can't find session"))
+	mock.SetResponse("gt session start", nil)
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	started, err := adapter.StartAgent(ctx, agent)
+	if err != nil || !started {
+		t.Fatalf("StartAgent() = %v, %v; want true, nil", started, err)
+	}
+	if !mock.Called("gt session start gastown/nux") {
+		t.Errorf("Expected gt session start, got %+v", mock.Calls)
+	}
+
+	stopped, err := adapter.StopAgent(ctx, agent)
+	if err != nil || stopped {
+		t.Errorf("StopAgent() = %v, %v; want false, nil", stopped, err)
+	}
+
+	// Session running: start is a no-op, stop kills it
+	mock = NewMockRunner()
+	mock.SetResponse("tmux has-session -t =gt-gastown-nux", nil)
+	mock.SetResponse("tmux kill-session", nil)
+	adapter = NewFSAdapterWithRunner(townRoot, mock)
+
+	started, err = adapter.StartAgent(ctx, agent)
+	if err != nil || started {
+		t.Errorf("StartAgent() = %v, %v; want false, nil", started, err)
+	}
+
+	stopped, err = adapter.StopAgent(ctx, agent)
+	if err != nil || !stopped {
+		t.Errorf("StopAgent() = %v, %v; want true, nil", stopped, err)
+	}
+	if !mock.Called("tmux kill-session -t =gt-gastown-nux") {
+		t.Errorf("Expected kill-session, got %+v", mock.Calls)
+	}
+	if mock.Called("gt ") {
+		t.Error("Expected no gt call when session already running")
+	}
+}
+
+func TestCachedAdapter_OnRefresh(t *testing.T) {
+	mock := NewMockRunner()
+	mock.SetResponse("tmux list-sessions", []byte(""))
+
+	cache := NewCachedAdapter(NewFSAdapterWithRunner(newTestTown(t), mock), time.Minute)
+
+	var seen []*Snapshot
+	cache.OnRefresh(func(snap *Snapshot) {
+		seen = append(seen, snap)
+	})
+
+	if _, err := cache.Agents(context.Background()); err != nil {
+		t.Fatalf("Agents() returned error: %v", err)
+	}
+	cache.Invalidate()
+	if _, err := cache.Agents(context.Background()); err != nil {
+		t.Fatalf("Agents() returned error: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("Expected an observer call per refresh, got %d", len(seen))
+	}
+	if len(seen[0].Agents) == 0 {
+		t.Error("Expected observer to receive scanned agents")
+	}
+}
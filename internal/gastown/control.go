@@ -0,0 +1,195 @@
+package gastown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Controller changes Gas Town state on behalf of an operator.
+type Controller interface {
+	// SendMail delivers a message to msg.To via `gt mail send`.
+	SendMail(ctx context.Context, msg Message) error
+
+	// Nudge types a prompt into an agent's tmux session and submits it.
+	Nudge(ctx context.Context, agent *Agent, prompt string) error
+
+	// StartAgent starts the agent's session unless it is already running and
+	// reports whether a session was started.
+	StartAgent(ctx context.Context, agent *Agent) (bool, error)
+
+	// StopAgent kills the agent's session if it is running and reports whether
+	// a session was stopped.
+	StopAgent(ctx context.Context, agent *Agent) (bool, error)
+
+	// RestartAgent stops the agent's session, if running, and starts it again.
+	RestartAgent(ctx context.Context, agent *Agent) error
+}
+
+// SendMail implements Controller.SendMail. The sender is taken from
+// msg.From when set, otherwise gt decides from the town root.
+func (a *FSAdapter) SendMail(ctx context.Context, msg Message) error {
+	if msg.To == "" {
+		return fmt.Errorf("mail recipient required")
+	}
+	if strings.TrimSpace(msg.Subject) == "" {
+		return fmt.Errorf("mail subject required")
+	}
+
+	args := []string{"mail", "send", msg.To, "-s", msg.Subject, "-m", msg.Body}
+	if msg.Priority != "" {
+		args = append(args, "--priority", msg.Priority)
+	}
+	if msg.Type != "" {
+		args = append(args, "--type", msg.Type)
+	}
+	if msg.ReplyTo != "" {
+		args = append(args, "--reply-to", msg.ReplyTo)
+	}
+
+	cmd := Command{Name: "gt", Args: args, Dir: a.townRoot}
+	if msg.From != "" {
+		cmd.Env = []string{fmt.Sprintf("GT_ROLE=%s", msg.From)}
+	}
+
+	_, err := a.runner.Run(ctx, cmd)
+	return err
+}
+
+// Nudge implements Controller.Nudge. The prompt is sent literally so tmux
+// key names in it are not interpreted, then submitted with Enter.
+func (a *FSAdapter) Nudge(ctx context.Context, agent *Agent, prompt string) error {
+	if strings.TrimSpace(prompt) == "" {
+		return fmt.Errorf("nudge message required")
+	}
+
+	session := agent.Session
+	if session == "" {
+		session = a.getSessionName(agent)
+	}
+	if session == "" {
+		return fmt.Errorf("no tmux session for agent %s", agent.Address())
+	}
+
+	if _, err := a.runner.Run(ctx, Command{
+		Name: "tmux",
+		Args: []string{"send-keys", "-t", paneTarget(session), "-l", prompt},
+	}); err != nil {
+		return err
+	}
+
+	_, err := a.runner.Run(ctx, Command{
+		Name: "tmux",
+		Args: []string{"send-keys", "-t", paneTarget(session), "Enter"},
+	})
+	return err
+}
+
+// StartAgent implements Controller.StartAgent using the gt command for the
+// agent's role.
+func (a *FSAdapter) StartAgent(ctx context.Context, agent *Agent) (bool, error) {
+	session := a.getSessionName(agent)
+	if session == "" {
+		return false, fmt.Errorf("no tmux session for agent %s", agent.Address())
+	}
+	if a.sessionExists(ctx, session) {
+		return false, nil
+	}
+
+	cmd, err := a.startCommand(agent)
+	if err != nil {
+		return false, err
+	}
+	if _, err := a.runner.Run(ctx, cmd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// StopAgent implements Controller.StopAgent by killing the agent's tmux session.
+func (a *FSAdapter) StopAgent(ctx context.Context, agent *Agent) (bool, error) {
+	session := a.getSessionName(agent)
+	if session == "" {
+		return false, fmt.Errorf("no tmux session for agent %s", agent.Address())
+	}
+	if !a.sessionExists(ctx, session) {
+		return false, nil
+	}
+
+	if _, err := a.runner.Run(ctx, Command{
+		Name: "tmux",
+		Args: []string{"kill-session", "-t", "=" + session},
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RestartAgent implements Controller.RestartAgent.
+func (a *FSAdapter) RestartAgent(ctx context.Context, agent *Agent) error {
+	if _, err := a.StopAgent(ctx, agent); err != nil {
+		return err
+	}
+	_, err := a.StartAgent(ctx, agent)
+	return err
+}
+
+// sessionExists reports whether a tmux session with exactly this name exists.
+func (a *FSAdapter) sessionExists(ctx context.Context, session string) bool {
+	_, err := a.runner.Run(ctx, Command{
+		Name: "tmux",
+		Args: []string{"has-session", "-t", "=" + session},
+	})
+	return err == nil
+}
+
+// startCommand returns the gt invocation that spawns an agent's session.
+func (a *FSAdapter) startCommand(agent *Agent) (Command, error) {
+	var args []string
+	switch agent.Role {
+	case RoleMayor:
+		args = []string{"mayor", "start"}
+	case RoleDeacon:
+		args = []string{"deacon", "start"}
+	case RoleWitness:
+		args = []string{"witness", "start", agent.Rig}
+	case RoleRefinery:
+		args = []string{"refinery", "start", agent.Rig}
+	case RolePolecat:
+		args = []string{"session", "start", agent.Address()}
+	case RoleCrew:
+		args = []string{"crew", "start", agent.Name, "--rig", agent.Rig}
+	default:
+		return Command{}, fmt.Errorf("cannot start agent with role %q", agent.Role)
+	}
+	return Command{Name: "gt", Args: args, Dir: a.townRoot}, nil
+}
+
+// SendMail implements Controller.SendMail.
+func (c *CachedAdapter) SendMail(ctx context.Context, msg Message) error {
+	return c.source.SendMail(ctx, msg)
+}
+
+// Nudge implements Controller.Nudge.
+func (c *CachedAdapter) Nudge(ctx context.Context, agent *Agent, prompt string) error {
+	return c.source.Nudge(ctx, agent, prompt)
+}
+
+// StartAgent implements Controller.StartAgent. The snapshot is invalidated
+// so the next read reflects the new session.
+func (c *CachedAdapter) StartAgent(ctx context.Context, agent *Agent) (bool, error) {
+	defer c.Invalidate()
+	return c.source.StartAgent(ctx, agent)
+}
+
+// StopAgent implements Controller.StopAgent.
+func (c *CachedAdapter) StopAgent(ctx context.Context, agent *Agent) (bool, error) {
+	defer c.Invalidate()
+	return c.source.StopAgent(ctx, agent)
+}
+
+// RestartAgent implements Controller.RestartAgent.
+func (c *CachedAdapter) RestartAgent(ctx context.Context, agent *Agent) error {
+	defer c.Invalidate()
+	return c.source.RestartAgent(ctx, agent)
+}
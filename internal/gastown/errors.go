@@ -0,0 +1,157 @@
+package gastown
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TownNotFoundError indicates there is no Gas Town at the configured root.
+type TownNotFoundError struct {
+	Root string
+}
+
+func (e *TownNotFoundError) Error() string {
+	return fmt.Sprintf("town not found at %s", e.Root)
+}
+
+// RigNotFoundError indicates the requested rig does not exist in the town.
+type RigNotFoundError struct {
+	Name string
+}
+
+func (e *RigNotFoundError) Error() string {
+	return fmt.Sprintf("rig not found: %s", e.Name)
+}
+
+// NotFoundError indicates the requested agent, convoy or molecule was not found.
+type NotFoundError struct {
+	Kind string // "agent", "convoy" or "molecule"
+	ID   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.Kind, e.ID)
+}
+
+// GTNotFoundError indicates the gt CLI is not installed or not in PATH.
+type GTNotFoundError struct{}
+
+func (e *GTNotFoundError) Error() string {
+	return "gt CLI not found in PATH. Install Gas Town and make sure gt is on your PATH"
+}
+
+// GTExecutionError indicates a gt command failed.
+type GTExecutionError struct {
+	Command string // Full command line, e.g. "gt convoy list --json"
+	Stderr  string
+	Err     error
+}
+
+func (e *GTExecutionError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s failed: %v: %s", e.Command, e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("%s failed: %v", e.Command, e.Err)
+}
+
+func (e *GTExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// CommandNotFoundError indicates a binary other than gt, such as tmux or
+// git, is not installed or not in PATH.
+type CommandNotFoundError struct {
+	Name string
+}
+
+func (e *CommandNotFoundError) Error() string {
+	return fmt.Sprintf("%s not found in PATH", e.Name)
+}
+
+// CommandError indicates a command other than gt, such as tmux or git, failed.
+type CommandError struct {
+	Name    string // Binary, e.g. "tmux"
+	Command string // Full command line, e.g. "tmux capture-pane -p -t =gt-mayor:"
+	Stderr  string
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s failed: %v: %s", e.Command, e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("%s failed: %v", e.Command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError indicates failure to parse gt output, or a town file such as
+// a refinery queue.json when File is set.
+type ParseError struct {
+	Command string
+	File    string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("failed to parse %s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("failed to parse gt %s output: %v", e.Command, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// IsTownNotFoundError checks if the error indicates the town does not exist.
+func IsTownNotFoundError(err error) bool {
+	var e *TownNotFoundError
+	return errors.As(err, &e)
+}
+
+// IsRigNotFoundError checks if the error indicates a rig was not found.
+func IsRigNotFoundError(err error) bool {
+	var e *RigNotFoundError
+	return errors.As(err, &e)
+}
+
+// IsNotFoundError checks if the error indicates an agent, convoy or
+// molecule was not found.
+func IsNotFoundError(err error) bool {
+	var e *NotFoundError
+	return errors.As(err, &e)
+}
+
+// IsGTNotFoundError checks if the error indicates gt is not installed.
+func IsGTNotFoundError(err error) bool {
+	var e *GTNotFoundError
+	return errors.As(err, &e)
+}
+
+// IsGTExecutionError checks if the error indicates a gt command failed.
+func IsGTExecutionError(err error) bool {
+	var e *GTExecutionError
+	return errors.As(err, &e)
+}
+
+// IsCommandNotFoundError checks if the error indicates tmux, git or another
+// binary besides gt is not installed.
+func IsCommandNotFoundError(err error) bool {
+	var e *CommandNotFoundError
+	return errors.As(err, &e)
+}
+
+// IsCommandError checks if the error indicates a command besides gt failed.
+func IsCommandError(err error) bool {
+	var e *CommandError
+	return errors.As(err, &e)
+}
+
+// IsParseError checks if the error is a parse error.
+func IsParseError(err error) bool {
+	var e *ParseError
+	return errors.As(err, &e)
+}
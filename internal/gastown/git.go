@@ -0,0 +1,160 @@
+package gastown
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitStatus describes the state of an agent's git worktree.
+type GitStatus struct {
+	Branch     string    `json:"branch,omitempty"`
+	Head       string    `json:"head"`
+	Detached   bool      `json:"detached,omitempty"`
+	Base       string    `json:"base,omitempty"`
+	Ahead      int       `json:"ahead"`
+	Behind     int       `json:"behind"`
+	Dirty      int       `json:"dirty"`
+	LastCommit time.Time `json:"last_commit,omitempty"`
+}
+
+// gitStatus reads branch and HEAD from the worktree's .git metadata and asks
+// git for dirty files, ahead/behind counts against the rig's main branch and
+// the last commit time. It returns nil if dir is not a git worktree.
+func (a *FSAdapter) gitStatus(ctx context.Context, dir string) *GitStatus {
+	gitDir, commonDir, ok := resolveGitDirs(dir)
+	if !ok {
+		return nil
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return nil
+	}
+
+	status := &GitStatus{}
+	headStr := strings.TrimSpace(string(head))
+	if ref, isRef := strings.CutPrefix(headStr, "ref: "); isRef {
+		status.Branch = strings.TrimPrefix(ref, "refs/heads/")
+		status.Head = resolveRef(gitDir, commonDir, ref)
+	} else {
+		status.Detached = true
+		status.Head = headStr
+	}
+
+	status.Base = baseBranch(gitDir, commonDir)
+
+	run := func(args ...string) ([]byte, error) {
+		return a.runner.Run(ctx, Command{Name: "git", Args: args, Dir: dir})
+	}
+
+	if out, err := run("status", "--porcelain"); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.TrimSpace(line) != "" {
+				status.Dirty++
+			}
+		}
+	}
+
+	if status.Base != "" && status.Head != "" {
+		if out, err := run("rev-list", "--left-right", "--count", status.Base+"...HEAD"); err == nil {
+			if fields := strings.Fields(string(out)); len(fields) == 2 {
+				status.Behind, _ = strconv.Atoi(fields[0])
+				status.Ahead, _ = strconv.Atoi(fields[1])
+			}
+		}
+	}
+
+	if status.Head != "" {
+		if out, err := run("log", "-1", "--format=%ct", "HEAD"); err == nil {
+			if secs, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+				status.LastCommit = time.Unix(secs, 0)
+			}
+		}
+	}
+
+	return status
+}
+
+// resolveGitDirs returns the worktree's git directory and the common
+// directory shared by all worktrees of the repository. A linked worktree has
+// a .git file pointing at <repo>/.git/worktrees/<name>.
+func resolveGitDirs(dir string) (gitDir, commonDir string, ok bool) {
+	dotGit := filepath.Join(dir, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", "", false
+	}
+
+	gitDir = dotGit
+	if !info.IsDir() {
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return "", "", false
+		}
+		target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !found {
+			return "", "", false
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(dir, gitDir)
+		}
+	}
+
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	return gitDir, filepath.Clean(commonDir), true
+}
+
+// resolveRef returns the commit a ref points to, checking loose refs in the
+// worktree and common directories and then packed-refs.
+func resolveRef(gitDir, commonDir, ref string) string {
+	for _, base := range []string{gitDir, commonDir} {
+		if data, err := os.ReadFile(filepath.Join(base, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+
+	f, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// baseBranch determines the branch worktrees are compared against: the
+// remote's default branch if known, otherwise a local main or master.
+func baseBranch(gitDir, commonDir string) string {
+	if data, err := os.ReadFile(filepath.Join(commonDir, "refs", "remotes", "origin", "HEAD")); err == nil {
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/remotes/"); ok {
+			return ref
+		}
+	}
+
+	for _, candidate := range []string{"main", "master"} {
+		if resolveRef(gitDir, commonDir, "refs/heads/"+candidate) != "" {
+			return candidate
+		}
+	}
+	return ""
+}
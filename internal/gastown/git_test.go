@@ -0,0 +1,96 @@
+package gastown
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile creates parent directories and writes content to path.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSAdapter_GitStatus_LinkedWorktree(t *testing.T) {
+	rigPath := t.TempDir()
+	repoGit := filepath.Join(rigPath, ".repo.git")
+	worktree := filepath.Join(rigPath, "polecats", "nux")
+
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: ../../.repo.git/worktrees/nux\n")
+	writeFile(t, filepath.Join(repoGit, "worktrees", "nux", "HEAD"), "ref: refs/heads/polecat/nux\n")
+	writeFile(t, filepath.Join(repoGit, "worktrees", "nux", "commondir"), "../..\n")
+	writeFile(t, filepath.Join(repoGit, "packed-refs"), "# pack-refs with: peeled\n"+
+		"1111111111111111111111111111111111111111 refs/heads/main\n"+
+		"2222222222222222222222222222222222222222 refs/heads/polecat/nux\n")
+
+	mock := NewMockRunner()
+	mock.SetResponse("git status --porcelain", []byte(" M main.go\n?? notes.txt\n"))
+	mock.SetResponse("git rev-list --left-right --count main...HEAD", []byte("1\t3\n"))
+	mock.SetResponse("git log -1", []byte("1767268800\n"))
+
+	adapter := NewFSAdapterWithRunner(rigPath, mock)
+	status := adapter.gitStatus(context.Background(), worktree)
+	if status == nil {
+		t.Fatal("Expected git status for worktree")
+	}
+
+	if status.Branch != "polecat/nux" {
+		t.Errorf("Expected branch polecat/nux, got %s", status.Branch)
+	}
+	if status.Head != "2222222222222222222222222222222222222222" {
+		t.Errorf("Expected HEAD from packed-refs, got %s", status.Head)
+	}
+	if status.Base != "main" {
+		t.Errorf("Expected base main, got %s", status.Base)
+	}
+	if status.Ahead != 3 || status.Behind != 1 {
+		t.Errorf("Expected ahead=3 behind=1, got ahead=%d behind=%d", status.Ahead, status.Behind)
+	}
+	if status.Dirty != 2 {
+		t.Errorf("Expected 2 dirty files, got %d", status.Dirty)
+	}
+	if !status.LastCommit.Equal(time.Unix(1767268800, 0)) {
+		t.Errorf("Unexpected last commit time %s", status.LastCommit)
+	}
+	for _, call := range mock.Calls {
+		if call.Dir != worktree {
+			t.Errorf("Expected git to run in worktree, got %s", call.Dir)
+		}
+	}
+}
+
+func TestFSAdapter_GitStatus_DetachedWithOriginHead(t *testing.T) {
+	worktree := t.TempDir()
+	gitDir := filepath.Join(worktree, ".git")
+
+	writeFile(t, filepath.Join(gitDir, "HEAD"), "3333333333333333333333333333333333333333\n")
+	writeFile(t, filepath.Join(gitDir, "refs", "remotes", "origin", "HEAD"), "ref: refs/remotes/origin/trunk\n")
+
+	adapter := NewFSAdapterWithRunner(worktree, NewMockRunner())
+	status := adapter.gitStatus(context.Background(), worktree)
+	if status == nil {
+		t.Fatal("Expected git status for repository")
+	}
+
+	if !status.Detached || status.Branch != "" {
+		t.Errorf("Expected detached HEAD, got %+v", status)
+	}
+	if status.Base != "origin/trunk" {
+		t.Errorf("Expected base origin/trunk, got %s", status.Base)
+	}
+}
+
+func TestFSAdapter_GitStatus_NotARepo(t *testing.T) {
+	adapter := NewFSAdapterWithRunner(t.TempDir(), NewMockRunner())
+	if status := adapter.gitStatus(context.Background(), t.TempDir()); status != nil {
+		t.Errorf("Expected nil status outside a repository, got %+v", status)
+	}
+}
@@ -0,0 +1,190 @@
+package gastown
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxWorktreeScanEntries bounds how many entries latestModTime visits so a
+// huge worktree cannot stall a town scan.
+const maxWorktreeScanEntries = 5000
+
+// worktreeSkipDirs are directories ignored when looking for recent edits.
+var worktreeSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+}
+
+// HealthRule configures how an agent with a running session is classified.
+type HealthRule struct {
+	// StuckAfter is how long the agent may show no activity before it is
+	// considered stuck. Zero disables stuck detection.
+	StuckAfter time.Duration
+
+	// IdleAfter is how long an agent without hooked work may show no activity
+	// before it is considered idle. Zero disables idle detection.
+	IdleAfter time.Duration
+
+	// StuckWithoutHook allows agents with no hooked work to be classified
+	// stuck. When false they are reported idle instead.
+	StuckWithoutHook bool
+
+	// MaxCompaction is the seance compaction count at which an agent is
+	// considered stuck regardless of activity. Zero disables the check.
+	MaxCompaction int
+}
+
+// HealthRules maps agent roles to their health rule.
+type HealthRules map[Role]HealthRule
+
+// DefaultHealthRules returns the built-in rules. Workers (polecats, crew) are
+// expected to produce output continuously and are stuck once quiet too long,
+// hooked or not; patrol agents (witness, refinery, deacon, mayor)
+// legitimately sit quiet between events.
+func DefaultHealthRules() HealthRules {
+	worker := HealthRule{
+		StuckAfter:       10 * time.Minute,
+		IdleAfter:        2 * time.Minute,
+		StuckWithoutHook: true,
+	}
+	patrol := HealthRule{
+		StuckAfter: 30 * time.Minute,
+		IdleAfter:  10 * time.Minute,
+	}
+	return HealthRules{
+		RolePolecat:  worker,
+		RoleCrew:     worker,
+		RoleWitness:  patrol,
+		RoleRefinery: patrol,
+		RoleDeacon:   patrol,
+		RoleMayor:    patrol,
+	}
+}
+
+// For returns the rule for a role, falling back to the default rule.
+func (r HealthRules) For(role Role) HealthRule {
+	if rule, ok := r[role]; ok {
+		return rule
+	}
+	if rule, ok := DefaultHealthRules()[role]; ok {
+		return rule
+	}
+	return DefaultHealthRules()[RolePolecat]
+}
+
+// ActivitySignals are the observations used to classify an agent.
+type ActivitySignals struct {
+	SessionRunning bool
+	HookAttached   bool
+	Compaction     int
+	WorktreeChange time.Time // newest file modification in the worktree
+	SeanceChange   time.Time // last write of .claude/seance.json
+	HookChange     time.Time // last write of .claude/hook.json
+	PaneActivity   time.Time // last tmux session activity
+}
+
+// LastActivity returns the most recent activity and the signal it came from.
+func (s ActivitySignals) LastActivity() (time.Time, string) {
+	var latest time.Time
+	var source string
+	for _, c := range []struct {
+		t      time.Time
+		source string
+	}{
+		{s.WorktreeChange, "worktree"},
+		{s.SeanceChange, "seance"},
+		{s.HookChange, "hook"},
+		{s.PaneActivity, "tmux"},
+	} {
+		if c.t.After(latest) {
+			latest = c.t
+			source = c.source
+		}
+	}
+	return latest, source
+}
+
+// Classify determines an agent's status from its activity signals and
+// returns a human-readable reason for the classification.
+func (r HealthRule) Classify(s ActivitySignals, now time.Time) (AgentStatus, string) {
+	if !s.SessionRunning {
+		return StatusOffline, "no tmux session"
+	}
+
+	if r.MaxCompaction > 0 && s.Compaction >= r.MaxCompaction {
+		return StatusStuck, fmt.Sprintf("compaction %d reached limit %d", s.Compaction, r.MaxCompaction)
+	}
+
+	last, source := s.LastActivity()
+	if last.IsZero() {
+		return StatusActive, "session running, no activity recorded"
+	}
+
+	quiet := now.Sub(last)
+	if quiet < 0 {
+		quiet = 0
+	}
+
+	if r.StuckAfter > 0 && quiet > r.StuckAfter && (s.HookAttached || r.StuckWithoutHook) {
+		work := "with hooked work"
+		if !s.HookAttached {
+			work = "and no hooked work"
+		}
+		return StatusStuck, fmt.Sprintf("no activity for %s %s (stuck after %s, last %s)",
+			quiet.Round(time.Second), work, r.StuckAfter, source)
+	}
+
+	if r.IdleAfter > 0 && quiet > r.IdleAfter && !s.HookAttached {
+		return StatusIdle, fmt.Sprintf("no hooked work and no activity for %s (idle after %s)",
+			quiet.Round(time.Second), r.IdleAfter)
+	}
+
+	return StatusActive, fmt.Sprintf("last activity %s ago (%s)", quiet.Round(time.Second), source)
+}
+
+// latestModTime returns the newest modification time of any file under dir,
+// skipping VCS and dependency directories and stopping after a bounded number
+// of entries.
+func latestModTime(dir string) time.Time {
+	var latest time.Time
+	visited := 0
+
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && path != dir && worktreeSkipDirs[d.Name()] {
+			return fs.SkipDir
+		}
+
+		visited++
+		if visited > maxWorktreeScanEntries {
+			return fs.SkipAll
+		}
+
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+
+	return latest
+}
+
+// fileModTime returns the modification time of path, or zero if missing.
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
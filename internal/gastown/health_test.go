@@ -0,0 +1,144 @@
+package gastown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthRule_Classify(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rule := HealthRule{
+		StuckAfter:    10 * time.Minute,
+		IdleAfter:     2 * time.Minute,
+		MaxCompaction: 5,
+	}
+
+	tests := []struct {
+		name    string
+		signals ActivitySignals
+		want    AgentStatus
+		reason  string
+	}{
+		{
+			name:    "no session",
+			signals: ActivitySignals{},
+			want:    StatusOffline,
+			reason:  "no tmux session",
+		},
+		{
+			name:    "recent worktree edit",
+			signals: ActivitySignals{SessionRunning: true, HookAttached: true, WorktreeChange: now.Add(-time.Minute)},
+			want:    StatusActive,
+			reason:  "worktree",
+		},
+		{
+			name: "quiet worktree but busy pane",
+			signals: ActivitySignals{
+				SessionRunning: true,
+				HookAttached:   true,
+				WorktreeChange: now.Add(-time.Hour),
+				PaneActivity:   now.Add(-30 * time.Second),
+			},
+			want:   StatusActive,
+			reason: "tmux",
+		},
+		{
+			name:    "hooked and quiet",
+			signals: ActivitySignals{SessionRunning: true, HookAttached: true, WorktreeChange: now.Add(-15 * time.Minute)},
+			want:    StatusStuck,
+			reason:  "stuck after 10m",
+		},
+		{
+			name:    "unhooked and quiet",
+			signals: ActivitySignals{SessionRunning: true, WorktreeChange: now.Add(-15 * time.Minute)},
+			want:    StatusIdle,
+			reason:  "no hooked work",
+		},
+		{
+			name:    "compaction limit",
+			signals: ActivitySignals{SessionRunning: true, HookAttached: true, Compaction: 5, WorktreeChange: now},
+			want:    StatusStuck,
+			reason:  "compaction 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := rule.Classify(tt.signals, now)
+			if status != tt.want {
+				t.Errorf("expected status %s, got %s (%s)", tt.want, status, reason)
+			}
+			if !strings.Contains(reason, tt.reason) {
+				t.Errorf("expected reason to contain %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+}
+
+func TestDefaultHealthRules_UnhookedQuiet(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rules := DefaultHealthRules()
+	signals := ActivitySignals{SessionRunning: true, WorktreeChange: now.Add(-15 * time.Minute)}
+
+	// A worker quiet past StuckAfter is stuck even without hooked work
+	if status, reason := rules.For(RolePolecat).Classify(signals, now); status != StatusStuck {
+		t.Errorf("polecat: expected stuck, got %s (%s)", status, reason)
+	}
+	if status, reason := rules.For(RoleCrew).Classify(signals, now); status != StatusStuck {
+		t.Errorf("crew: expected stuck, got %s (%s)", status, reason)
+	}
+
+	// Patrol agents without hooked work are only idle
+	if status, reason := rules.For(RoleWitness).Classify(signals, now); status != StatusIdle {
+		t.Errorf("witness: expected idle, got %s (%s)", status, reason)
+	}
+}
+
+func TestHealthRules_For(t *testing.T) {
+	rules := HealthRules{
+		RolePolecat: {StuckAfter: time.Minute},
+	}
+
+	if got := rules.For(RolePolecat).StuckAfter; got != time.Minute {
+		t.Errorf("expected configured polecat rule, got %s", got)
+	}
+	if got := rules.For(RoleWitness); got != DefaultHealthRules()[RoleWitness] {
+		t.Errorf("expected default witness rule, got %+v", got)
+	}
+}
+
+func TestLatestModTime_SkipsGitDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	recent := time.Now().Add(-time.Minute)
+
+	srcFile := filepath.Join(tmpDir, "src", "main.go")
+	gitFile := filepath.Join(tmpDir, ".git", "index")
+	for _, path := range []string{srcFile, gitFile} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{tmpDir, filepath.Join(tmpDir, "src"), filepath.Join(tmpDir, ".git")} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(srcFile, recent, recent); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(gitFile, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := latestModTime(tmpDir)
+	if !got.Equal(recent) {
+		t.Errorf("expected %s, got %s", recent, got)
+	}
+}
@@ -0,0 +1,135 @@
+package gastown
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Thread is a conversation grouped by reply chain or subject.
+type Thread struct {
+	ID            string    `json:"id"`
+	Subject       string    `json:"subject"`
+	Participants  []string  `json:"participants"`
+	Messages      []Message `json:"messages"`
+	Unread        int       `json:"unread"`
+	LastMessageAt time.Time `json:"last_message_at"`
+}
+
+// subjectPrefixes are reply/forward markers ignored when grouping by subject.
+var subjectPrefixes = []string{"re:", "fwd:", "fw:"}
+
+// GroupThreads groups messages into threads. Messages carrying a thread ID
+// or a reply chain are grouped with their root; all others are grouped by
+// subject with reply and forward prefixes removed. Threads are ordered by
+// their most recent message, messages within a thread oldest first.
+func GroupThreads(messages []Message) []Thread {
+	byID := make(map[string]Message, len(messages))
+	for _, msg := range messages {
+		if msg.ID != "" {
+			byID[msg.ID] = msg
+		}
+	}
+
+	threads := make(map[string]*Thread)
+	var order []string
+
+	for _, msg := range messages {
+		key := threadKey(msg, byID)
+
+		thread, ok := threads[key]
+		if !ok {
+			thread = &Thread{
+				ID:           key,
+				Subject:      strings.TrimSpace(stripSubjectPrefixes(msg.Subject)),
+				Participants: []string{},
+			}
+			threads[key] = thread
+			order = append(order, key)
+		}
+
+		thread.Messages = append(thread.Messages, msg)
+		if !msg.Read {
+			thread.Unread++
+		}
+		if msg.Timestamp.After(thread.LastMessageAt) {
+			thread.LastMessageAt = msg.Timestamp
+		}
+		for _, p := range []string{msg.From, msg.To} {
+			if p != "" && !contains(thread.Participants, p) {
+				thread.Participants = append(thread.Participants, p)
+			}
+		}
+	}
+
+	result := make([]Thread, 0, len(order))
+	for _, key := range order {
+		thread := threads[key]
+		sort.SliceStable(thread.Messages, func(i, j int) bool {
+			return thread.Messages[i].Timestamp.Before(thread.Messages[j].Timestamp)
+		})
+		if thread.Subject == "" && len(thread.Messages) > 0 {
+			thread.Subject = thread.Messages[0].Subject
+		}
+		result = append(result, *thread)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].LastMessageAt.After(result[j].LastMessageAt)
+	})
+
+	return result
+}
+
+// threadKey returns the grouping key for a message: the thread ID of the
+// root of its reply chain if any, otherwise the root's normalized subject.
+func threadKey(msg Message, byID map[string]Message) string {
+	root := msg
+	visited := make(map[string]bool)
+	for root.ThreadID == "" && root.ReplyTo != "" && !visited[root.ID] {
+		visited[root.ID] = true
+		parent, ok := byID[root.ReplyTo]
+		if !ok {
+			break
+		}
+		root = parent
+	}
+
+	if root.ThreadID != "" {
+		return root.ThreadID
+	}
+
+	subject := strings.ToLower(stripSubjectPrefixes(root.Subject))
+	if subject == "" {
+		return root.ID
+	}
+	return "subject:" + subject
+}
+
+// stripSubjectPrefixes removes any number of leading Re:/Fwd: markers.
+func stripSubjectPrefixes(subject string) string {
+	for {
+		trimmed := strings.TrimSpace(subject)
+		lower := strings.ToLower(trimmed)
+		stripped := false
+		for _, prefix := range subjectPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				subject = trimmed[len(prefix):]
+				stripped = true
+				break
+			}
+		}
+		if !stripped {
+			return trimmed
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package gastown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupThreads(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	messages := []Message{
+		{ID: "m1", From: "mayor/", To: "gastown/nux", Subject: "Deploy plan", Timestamp: base, Read: true},
+		{ID: "m2", From: "gastown/nux", To: "mayor/", Subject: "Ack", ReplyTo: "m1", Timestamp: base.Add(time.Minute)},
+		{ID: "m3", From: "mayor/", To: "gastown/nux", Subject: "RE: Re: deploy plan", Timestamp: base.Add(2 * time.Minute)},
+		{ID: "m4", From: "gastown/witness", To: "gastown/nux", Subject: "Stuck?", Timestamp: base.Add(30 * time.Second)},
+	}
+
+	threads := GroupThreads(messages)
+	if len(threads) != 2 {
+		t.Fatalf("Expected 2 threads, got %d: %+v", len(threads), threads)
+	}
+
+	deploy := threads[0]
+	if deploy.Subject != "Deploy plan" {
+		t.Errorf("Expected newest thread Deploy plan first, got %q", deploy.Subject)
+	}
+	if len(deploy.Messages) != 3 {
+		t.Fatalf("Expected 3 messages in thread, got %d", len(deploy.Messages))
+	}
+	if deploy.Messages[0].ID != "m1" || deploy.Messages[2].ID != "m3" {
+		t.Errorf("Expected messages oldest first, got %s..%s", deploy.Messages[0].ID, deploy.Messages[2].ID)
+	}
+	if deploy.Unread != 2 {
+		t.Errorf("Expected 2 unread, got %d", deploy.Unread)
+	}
+	if len(deploy.Participants) != 2 {
+		t.Errorf("Expected 2 participants, got %v", deploy.Participants)
+	}
+	if !deploy.LastMessageAt.Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("Unexpected last message time %s", deploy.LastMessageAt)
+	}
+}
+
+func TestGroupThreads_ThreadID(t *testing.T) {
+	messages := []Message{
+		{ID: "a", Subject: "One", ThreadID: "t-1"},
+		{ID: "b", Subject: "Two", ThreadID: "t-1"},
+		{ID: "c", Subject: "One"},
+	}
+
+	threads := GroupThreads(messages)
+	if len(threads) != 2 {
+		t.Fatalf("Expected 2 threads, got %d", len(threads))
+	}
+	for _, thread := range threads {
+		if thread.ID == "t-1" && len(thread.Messages) != 2 {
+			t.Errorf("Expected 2 messages in t-1, got %d", len(thread.Messages))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gastown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPaneLines is the number of lines captured when none is requested.
+	DefaultPaneLines = 50
+
+	// MaxPaneLines caps how much scrollback a single capture may return.
+	MaxPaneLines = 2000
+)
+
+// Pane is a capture of the visible output of an agent's tmux session.
+type Pane struct {
+	Address    string    `json:"address"`
+	Session    string    `json:"session"`
+	Lines      []string  `json:"lines"`
+	ANSI       bool      `json:"ansi"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// Content returns the captured lines joined with newlines.
+func (p *Pane) Content() string {
+	return strings.Join(p.Lines, "\n")
+}
+
+// Pane captures the last lines of an agent's tmux session. When ansi is true,
+// escape sequences for colors and attributes are preserved.
+func (a *FSAdapter) Pane(ctx context.Context, agent *Agent, lines int, ansi bool) (*Pane, error) {
+	if lines <= 0 {
+		lines = DefaultPaneLines
+	}
+	if lines > MaxPaneLines {
+		lines = MaxPaneLines
+	}
+
+	session := agent.Session
+	if session == "" {
+		session = a.getSessionName(agent)
+	}
+	if session == "" {
+		return nil, fmt.Errorf("no tmux session for agent %s", agent.Address())
+	}
+
+	args := []string{"capture-pane", "-p", "-J", "-t", paneTarget(session), "-S", fmt.Sprintf("-%d", lines)}
+	if ansi {
+		args = append(args, "-e")
+	}
+
+	output, err := a.runner.Run(ctx, Command{Name: "tmux", Args: args})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pane{
+		Address:    agent.Address(),
+		Session:    session,
+		Lines:      lastLines(string(output), lines),
+		ANSI:       ansi,
+		CapturedAt: time.Now(),
+	}, nil
+}
+
+// lastLines splits output into lines, drops trailing blank lines (tmux pads
+// the visible area) and returns at most n of the remaining lines.
+func lastLines(output string, n int) []string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+	return lines
+}
+
+// paneTarget returns the tmux target for the active pane of session. "="
+// matches the session name exactly rather than as a prefix, and the
+// trailing ":" is needed because pane commands do not accept a bare
+// "=name" target.
+func paneTarget(session string) string {
+	return "=" + session + ":"
+}
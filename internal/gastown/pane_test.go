@@ -0,0 +1,28 @@
+package gastown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		n      int
+		want   []string
+	}{
+		{"trailing padding", "a\nb\n\n\n  \n", 10, []string{"a", "b"}},
+		{"truncate", "1\n2\n3\n4\n", 2, []string{"3", "4"}},
+		{"empty", "\n\n", 5, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lastLines(tt.output, tt.n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
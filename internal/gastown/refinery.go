@@ -0,0 +1,238 @@
+package gastown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeRequest is a branch waiting in a rig's refinery merge queue.
+type MergeRequest struct {
+	Position   int       `json:"position"`
+	Branch     string    `json:"branch"`
+	Molecule   string    `json:"molecule,omitempty"`
+	Issue      string    `json:"issue,omitempty"`
+	Agent      string    `json:"agent,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	QueuedAt   time.Time `json:"queued_at,omitempty"`
+	AgeSeconds float64   `json:"age_seconds"`
+	Conflicts  bool      `json:"conflicts"`
+
+	// ConflictCheck is "clean", "conflicts" or "unknown" when the check
+	// could not run; ConflictError then says why, if git failed.
+	ConflictCheck string `json:"conflict_check"`
+	ConflictError string `json:"conflict_error,omitempty"`
+}
+
+// Conflict check results.
+const (
+	ConflictClean   = "clean"
+	ConflictFound   = "conflicts"
+	ConflictUnknown = "unknown"
+)
+
+// MergeQueue is the refinery's ordered list of pending merges for a rig.
+type MergeQueue struct {
+	Rig      string         `json:"rig"`
+	Base     string         `json:"base,omitempty"`
+	Source   string         `json:"source"` // "gt", "file" or "none"
+	Refinery *Agent         `json:"refinery,omitempty"`
+	Entries  []MergeRequest `json:"entries"`
+}
+
+// rawMergeRequest accepts the field names used by gt mq and queue.json.
+type rawMergeRequest struct {
+	Position  int       `json:"position,omitempty"`
+	Branch    string    `json:"branch"`
+	Molecule  string    `json:"molecule,omitempty"`
+	Issue     string    `json:"issue,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Polecat   string    `json:"polecat,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	QueuedAt  time.Time `json:"queued_at,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// MergeQueue returns the refinery merge queue for a rig. The queue is read
+// from `gt mq list <rig> --json`, falling back to refinery/queue.json in the
+// rig directory. Each entry is checked for conflicts against the base branch.
+func (a *FSAdapter) MergeQueue(ctx context.Context, rig string) (*MergeQueue, error) {
+	rigPath := filepath.Join(a.townRoot, rig)
+	if !a.dirExists(rigPath) {
+		return nil, &RigNotFoundError{Name: rig}
+	}
+
+	queue := &MergeQueue{
+		Rig:     rig,
+		Source:  "none",
+		Entries: []MergeRequest{},
+	}
+
+	if a.dirExists(filepath.Join(rigPath, "refinery")) {
+		refinery := &Agent{Role: RoleRefinery, Name: "refinery", Rig: rig}
+		a.enrichAgent(refinery, a.getTmuxSessions(ctx))
+		queue.Refinery = refinery
+	}
+
+	raw, source, err := a.readMergeQueue(ctx, rig, rigPath)
+	if err != nil {
+		return nil, err
+	}
+	queue.Source = source
+
+	// Honor explicit positions when every entry has one; otherwise keep the
+	// queue's own order
+	positioned := true
+	for _, r := range raw {
+		if r.Position <= 0 {
+			positioned = false
+			break
+		}
+	}
+	if positioned {
+		sort.SliceStable(raw, func(i, j int) bool {
+			return raw[i].Position < raw[j].Position
+		})
+	}
+
+	repoDir := a.refineryRepo(rigPath)
+	if repoDir != "" {
+		gitDir, commonDir, _ := resolveGitDirs(repoDir)
+		queue.Base = baseBranch(gitDir, commonDir)
+	}
+
+	now := time.Now()
+	for i, r := range raw {
+		mr := MergeRequest{
+			Position: i + 1,
+			Branch:   r.Branch,
+			Molecule: r.Molecule,
+			Issue:    r.Issue,
+			Agent:    r.Agent,
+			Status:   r.Status,
+			QueuedAt: r.QueuedAt,
+		}
+		if mr.Agent == "" && r.Polecat != "" {
+			mr.Agent = rig + "/" + r.Polecat
+		}
+		if mr.QueuedAt.IsZero() {
+			mr.QueuedAt = r.CreatedAt
+		}
+		if !mr.QueuedAt.IsZero() {
+			mr.AgeSeconds = now.Sub(mr.QueuedAt).Seconds()
+		}
+		mr.ConflictCheck = ConflictUnknown
+		if repoDir != "" && queue.Base != "" && mr.Branch != "" {
+			conflicts, err := a.mergeConflicts(ctx, repoDir, queue.Base, mr.Branch)
+			switch {
+			case err != nil:
+				mr.ConflictError = err.Error()
+			case conflicts:
+				mr.Conflicts = true
+				mr.ConflictCheck = ConflictFound
+			default:
+				mr.ConflictCheck = ConflictClean
+			}
+		}
+		queue.Entries = append(queue.Entries, mr)
+	}
+
+	return queue, nil
+}
+
+// readMergeQueue loads raw queue entries from gt, falling back to
+// queue.json only when gt is not installed or has no mq command. Any other
+// gt failure is returned.
+func (a *FSAdapter) readMergeQueue(ctx context.Context, rig, rigPath string) ([]rawMergeRequest, string, error) {
+	output, err := a.runner.Run(ctx, Command{
+		Name: "gt",
+		Args: []string{"mq", "list", rig, "--json"},
+		Dir:  a.townRoot,
+	})
+	if err == nil {
+		if len(strings.TrimSpace(string(output))) == 0 {
+			return nil, "gt", nil
+		}
+		var raw []rawMergeRequest
+		if err := json.Unmarshal(output, &raw); err != nil {
+			return nil, "", &ParseError{Command: "mq list", Err: err}
+		}
+		return raw, "gt", nil
+	}
+	if !IsGTNotFoundError(err) && !mqUnsupported(err) {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(rigPath, "refinery", "queue.json"))
+	if err != nil {
+		return nil, "none", nil
+	}
+
+	var raw []rawMergeRequest
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// queue.json may wrap entries in an object
+		var wrapped struct {
+			Queue []rawMergeRequest `json:"queue"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, "", &ParseError{File: "refinery/queue.json", Err: err}
+		}
+		raw = wrapped.Queue
+	}
+	return raw, "file", nil
+}
+
+// mqUnsupported reports whether a gt mq failure means the installed gt
+// predates merge queues.
+func mqUnsupported(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "unknown command") || strings.Contains(msg, "unknown subcommand")
+}
+
+// refineryRepo returns the git checkout the refinery merges in.
+func (a *FSAdapter) refineryRepo(rigPath string) string {
+	for _, dir := range []string{
+		filepath.Join(rigPath, "refinery", "rig"),
+		filepath.Join(rigPath, "refinery"),
+		filepath.Join(rigPath, "mayor", "rig"),
+		rigPath,
+	} {
+		if _, _, ok := resolveGitDirs(dir); ok {
+			return dir
+		}
+	}
+	return ""
+}
+
+// mergeConflicts reports whether merging branch into base would conflict,
+// using a trivial three-way merge-tree that never touches the worktree. An
+// error means the check could not be made.
+func (a *FSAdapter) mergeConflicts(ctx context.Context, repoDir, base, branch string) (bool, error) {
+	// merge-tree's trivial mode takes no "--", so refuse names git would
+	// read as options
+	for _, name := range []string{base, branch} {
+		if strings.HasPrefix(name, "-") {
+			return false, fmt.Errorf("invalid branch name %q", name)
+		}
+	}
+
+	run := func(args ...string) ([]byte, error) {
+		return a.runner.Run(ctx, Command{Name: "git", Args: args, Dir: repoDir})
+	}
+
+	mergeBase, err := run("merge-base", "--", base, branch)
+	if err != nil {
+		return false, err
+	}
+
+	output, err := run("merge-tree", strings.TrimSpace(string(mergeBase)), base, branch)
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(output), "\n+<<<<<<< ") || strings.HasPrefix(string(output), "+<<<<<<< "), nil
+}
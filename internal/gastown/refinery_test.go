@@ -0,0 +1,152 @@
+package gastown
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFSAdapter_MergeQueue_FromFile(t *testing.T) {
+	townRoot := newTestTown(t)
+	rigPath := filepath.Join(townRoot, "gastown")
+	repo := filepath.Join(rigPath, "refinery", "rig")
+
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(repo, ".git", "refs", "heads", "main"), "1111111111111111111111111111111111111111\n")
+
+	queued := time.Now().Add(-30 * time.Minute).UTC().Format(time.RFC3339)
+	writeFile(t, filepath.Join(rigPath, "refinery", "queue.json"), `{"queue": [
+		{"position": 2, "branch": "polecat/slit", "polecat": "slit", "queued_at": "`+queued+`"},
+		{"position": 1, "branch": "polecat/nux", "agent": "gastown/nux", "molecule": "mol-1"}
+	]}`)
+
+	mock := NewMockRunner()
+	mock.SetError("gt mq", fmt.Errorf("gt: unknown command mq"))
+	mock.SetResponse("git merge-base", []byte("abc123\n"))
+	mock.SetResponse("git merge-tree abc123 main polecat/nux", []byte("merged\n  result 100644 aaa main.go\n"))
+	mock.SetResponse("git merge-tree abc123 main polecat/slit", []byte("changed in both\n@@ -1 +1,5 @@\n+<<<<<<< .our\n+a\n+=======\n+b\n+>>>>>>> .their\n"))
+
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	queue, err := adapter.MergeQueue(context.Background(), "gastown")
+	if err != nil {
+		t.Fatalf("MergeQueue() returned error: %v", err)
+	}
+
+	if queue.Source != "file" {
+		t.Errorf("Expected source file, got %s", queue.Source)
+	}
+	if queue.Base != "main" {
+		t.Errorf("Expected base main, got %s", queue.Base)
+	}
+	if len(queue.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(queue.Entries))
+	}
+
+	first, second := queue.Entries[0], queue.Entries[1]
+	if first.Branch != "polecat/nux" || first.Position != 1 || first.Conflicts {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+	if second.Branch != "polecat/slit" || !second.Conflicts {
+		t.Errorf("Expected second entry to conflict: %+v", second)
+	}
+	if second.Agent != "gastown/slit" {
+		t.Errorf("Expected agent gastown/slit, got %s", second.Agent)
+	}
+	if second.AgeSeconds < 29*60 {
+		t.Errorf("Expected age of about 30m, got %vs", second.AgeSeconds)
+	}
+	if first.ConflictCheck != ConflictClean || second.ConflictCheck != ConflictFound {
+		t.Errorf("Expected clean then conflicts, got %s and %s", first.ConflictCheck, second.ConflictCheck)
+	}
+	if !mock.Called("git merge-base -- main polecat/nux") {
+		t.Error("Expected branch names after -- in git merge-base")
+	}
+
+	// A failing git is reported as unknown, not as clean
+	mock.SetError("git merge-base", &CommandError{Name: "git", Command: "git merge-base", Err: fmt.Errorf("exit status 128")})
+	queue, err = adapter.MergeQueue(context.Background(), "gastown")
+	if err != nil {
+		t.Fatalf("MergeQueue() returned error: %v", err)
+	}
+	for _, mr := range queue.Entries {
+		if mr.Conflicts || mr.ConflictCheck != ConflictUnknown || mr.ConflictError == "" {
+			t.Errorf("Expected unknown conflict state, got %+v", mr)
+		}
+	}
+}
+
+func TestMergeConflicts_RejectsOptionNames(t *testing.T) {
+	mock := NewMockRunner()
+	adapter := NewFSAdapterWithRunner(t.TempDir(), mock)
+
+	if _, err := adapter.mergeConflicts(context.Background(), t.TempDir(), "main", "--output=/tmp/x"); err == nil {
+		t.Error("Expected an error for a branch name starting with -")
+	}
+	if len(mock.Calls) != 0 {
+		t.Errorf("Expected no git calls, got %+v", mock.Calls)
+	}
+}
+
+func TestFSAdapter_MergeQueue_FromGT(t *testing.T) {
+	townRoot := newTestTown(t)
+
+	mock := NewMockRunner()
+	mock.SetResponse("gt mq list gastown --json", []byte(`[{"branch": "polecat/nux"}]`))
+
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+
+	queue, err := adapter.MergeQueue(context.Background(), "gastown")
+	if err != nil {
+		t.Fatalf("MergeQueue() returned error: %v", err)
+	}
+	if queue.Source != "gt" || len(queue.Entries) != 1 {
+		t.Errorf("Unexpected queue: %+v", queue)
+	}
+
+	// gt prints nothing for an empty queue
+	mock.SetResponse("gt mq list gastown --json", []byte(" \n"))
+	queue, err = adapter.MergeQueue(context.Background(), "gastown")
+	if err != nil {
+		t.Fatalf("MergeQueue() with empty output returned error: %v", err)
+	}
+	if queue.Source != "gt" || len(queue.Entries) != 0 {
+		t.Errorf("Expected empty queue, got %+v", queue)
+	}
+}
+
+func TestFSAdapter_MergeQueue_Errors(t *testing.T) {
+	townRoot := newTestTown(t)
+	writeFile(t, filepath.Join(townRoot, "gastown", "refinery", "queue.json"), `not json`)
+
+	mock := NewMockRunner()
+	adapter := NewFSAdapterWithRunner(townRoot, mock)
+	ctx := context.Background()
+
+	// gt failures are returned, not hidden behind the queue.json fallback
+	mock.SetError("gt mq", &GTExecutionError{Command: "gt mq list gastown --json", Err: fmt.Errorf("exit status 1")})
+	if _, err := adapter.MergeQueue(ctx, "gastown"); !IsGTExecutionError(err) {
+		t.Errorf("Expected GTExecutionError, got %v", err)
+	}
+
+	mock.SetError("gt mq", &GTExecutionError{Command: "gt mq list gastown --json", Stderr: `unknown command "mq" for "gt"`, Err: fmt.Errorf("exit status 1")})
+	if _, err := adapter.MergeQueue(ctx, "gastown"); !IsParseError(err) {
+		t.Errorf("Expected ParseError for a bad queue.json, got %v", err)
+	}
+
+	// Without gt installed, queue.json is still read
+	mock.SetError("gt mq", &GTNotFoundError{})
+	if _, err := adapter.MergeQueue(ctx, "gastown"); !IsParseError(err) {
+		t.Errorf("Expected ParseError for a bad queue.json without gt, got %v", err)
+	}
+}
+
+func TestFSAdapter_MergeQueue_UnknownRig(t *testing.T) {
+	adapter := NewFSAdapterWithRunner(newTestTown(t), NewMockRunner())
+
+	if _, err := adapter.MergeQueue(context.Background(), "nope"); err == nil {
+		t.Error("Expected error for unknown rig")
+	}
+}
@@ -0,0 +1,143 @@
+package gastown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/logging"
+)
+
+// Command describes an external command (gt, tmux) run by the adapter.
+type Command struct {
+	Name string   // Binary to run, e.g. "gt" or "tmux"
+	Args []string // Arguments passed to the binary
+	Dir  string   // Working directory; empty uses the current directory
+	Env  []string // Extra KEY=VALUE pairs appended to the process environment
+}
+
+// String returns the command line, e.g. "gt convoy list --json".
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Runner defines the interface for executing external commands.
+type Runner interface {
+	Run(ctx context.Context, cmd Command) ([]byte, error)
+}
+
+// DefaultRunner implements Runner using os/exec.
+type DefaultRunner struct{}
+
+// Run executes the command and returns stdout. Each run is logged with its
+// duration and exit code under the request ID in ctx. gt failures are
+// reported as *GTNotFoundError or *GTExecutionError, failures of other
+// commands as *CommandNotFoundError or *CommandError.
+func (r *DefaultRunner) Run(ctx context.Context, c Command) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+
+	if c.Dir != "" {
+		cmd.Dir = c.Dir
+	}
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	logging.Command(ctx, c.Name, c.Args, time.Since(start), stderr.String(), err)
+	if err != nil {
+		return nil, commandError(c, strings.TrimSpace(stderr.String()), err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// commandError wraps a failure of c in the typed error for its binary.
+func commandError(c Command, stderr string, err error) error {
+	notFound := errors.Is(err, exec.ErrNotFound)
+	if c.Name == "gt" {
+		if notFound {
+			return &GTNotFoundError{}
+		}
+		return &GTExecutionError{Command: c.String(), Stderr: stderr, Err: err}
+	}
+	if notFound {
+		return &CommandNotFoundError{Name: c.Name}
+	}
+	return &CommandError{Name: c.Name, Command: c.String(), Stderr: stderr, Err: err}
+}
+
+// MockRunner is a test double for Runner that records every call.
+type MockRunner struct {
+	Responses map[string][]byte
+	Errors    map[string]error
+	Calls     []Command
+	mu        sync.Mutex
+}
+
+// NewMockRunner creates a mock runner for testing.
+func NewMockRunner() *MockRunner {
+	return &MockRunner{
+		Responses: make(map[string][]byte),
+		Errors:    make(map[string]error),
+	}
+}
+
+// Run returns the pre-configured response for the longest matching command
+// prefix, e.g. "gt convoy list --json", then "gt convoy list", down to "gt".
+func (m *MockRunner) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Calls = append(m.Calls, cmd)
+
+	words := append([]string{cmd.Name}, cmd.Args...)
+	for n := len(words); n > 0; n-- {
+		key := strings.Join(words[:n], " ")
+		if err, ok := m.Errors[key]; ok {
+			return nil, err
+		}
+		if resp, ok := m.Responses[key]; ok {
+			return resp, nil
+		}
+	}
+
+	return nil, commandError(cmd, "", errors.New("mock: no response configured"))
+}
+
+// SetResponse sets a mock response for a command prefix.
+func (m *MockRunner) SetResponse(pattern string, response []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Responses[pattern] = response
+}
+
+// SetError sets a mock error for a command prefix.
+func (m *MockRunner) SetError(pattern string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Errors[pattern] = err
+}
+
+// Called reports whether a command starting with prefix was run.
+func (m *MockRunner) Called(prefix string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.Calls {
+		if strings.HasPrefix(c.String(), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,461 @@
+package gastown
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRefreshInterval is how often CachedAdapter rescans the town.
+	DefaultRefreshInterval = 10 * time.Second
+
+	// watchInterval is how often CachedAdapter checks the town tree for changes.
+	watchInterval = 2 * time.Second
+)
+
+// Snapshot is a consistent view of the town captured by a single scan.
+type Snapshot struct {
+	Town       *Town
+	Status     *TownStatus
+	Agents     []Agent
+	Molecules  []Molecule
+	Mail       []Message
+	MailErr    error // Set when any inbox could not be read
+	ConvoysErr error // Set when gt convoy list failed
+	TakenAt    time.Time
+	Err        error // Set when the town could not be scanned
+}
+
+// Age returns how long ago the snapshot was taken.
+func (s *Snapshot) Age() time.Duration {
+	return time.Since(s.TakenAt)
+}
+
+// CachedAdapter implements Adapter by serving reads from a snapshot of the
+// town that is refreshed in the background on an interval and whenever the
+// town tree changes on disk. Per-address mail and pane captures are always live.
+type CachedAdapter struct {
+	source   *FSAdapter
+	interval time.Duration
+
+	mu    sync.RWMutex
+	snap  *Snapshot
+	stale bool // set by Invalidate until the next refresh
+
+	refreshMu   sync.Mutex // serializes scans
+	fingerprint string     // last observed town tree fingerprint
+
+	observers []func(*Snapshot) // called after each refresh
+}
+
+// NewCachedAdapter creates a snapshot cache over source. A zero interval uses
+// DefaultRefreshInterval.
+func NewCachedAdapter(source *FSAdapter, interval time.Duration) *CachedAdapter {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	return &CachedAdapter{
+		source:   source,
+		interval: interval,
+	}
+}
+
+// Start refreshes the snapshot on the configured interval and when the town
+// tree changes, until ctx is canceled.
+func (c *CachedAdapter) Start(ctx context.Context) {
+	refreshTicker := time.NewTicker(c.interval)
+	defer refreshTicker.Stop()
+	watchTicker := time.NewTicker(watchInterval)
+	defer watchTicker.Stop()
+
+	_, _ = c.Refresh(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refreshTicker.C:
+			_, _ = c.Refresh(ctx)
+		case <-watchTicker.C:
+			c.mu.RLock()
+			last := c.fingerprint
+			c.mu.RUnlock()
+			if c.source.fingerprint() != last {
+				_, _ = c.Refresh(ctx)
+			}
+		}
+	}
+}
+
+// Refresh rescans the town and replaces the current snapshot. A scan that is
+// interrupted by ctx is discarded so a partial view is never served.
+func (c *CachedAdapter) Refresh(ctx context.Context) (*Snapshot, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	return c.refresh(ctx)
+}
+
+// refresh implements Refresh. Callers hold c.refreshMu.
+func (c *CachedAdapter) refresh(ctx context.Context) (*Snapshot, error) {
+	fingerprint := c.source.fingerprint()
+	snap := c.source.snapshot(ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.snap = snap
+	c.stale = false
+	c.fingerprint = fingerprint
+	observers := c.observers
+	c.mu.Unlock()
+
+	for _, fn := range observers {
+		fn(snap)
+	}
+
+	return snap, nil
+}
+
+// Snapshot returns the current snapshot, scanning synchronously if there is
+// none yet or it is older than the refresh interval. Concurrent stale reads
+// share one scan.
+func (c *CachedAdapter) Snapshot(ctx context.Context) (*Snapshot, error) {
+	if snap := c.fresh(); snap != nil {
+		return snap, nil
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	// Another request may have refreshed while we waited for the lock
+	if snap := c.fresh(); snap != nil {
+		return snap, nil
+	}
+	return c.refresh(ctx)
+}
+
+// OnRefresh registers fn to be called with every new snapshot. Observers
+// run on the refreshing goroutine and must not call Refresh.
+func (c *CachedAdapter) OnRefresh(fn func(*Snapshot)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observers = append(c.observers, fn)
+}
+
+// fresh returns the current snapshot if it may still be served.
+func (c *CachedAdapter) fresh() *Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.snap == nil || c.stale || c.snap.Age() >= c.interval {
+		return nil
+	}
+	return c.snap
+}
+
+// Invalidate marks the snapshot stale so the next read rescans the town.
+func (c *CachedAdapter) Invalidate() {
+	c.mu.Lock()
+	c.stale = true
+	c.mu.Unlock()
+}
+
+// SetHealthRules replaces the source's status classification rules and
+// marks the snapshot stale so the next read reclassifies agents.
+func (c *CachedAdapter) SetHealthRules(rules HealthRules) {
+	c.source.SetHealthRules(rules)
+	c.Invalidate()
+}
+
+// SnapshotTime returns when the current snapshot was taken, or zero if none.
+func (c *CachedAdapter) SnapshotTime() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.snap == nil {
+		return time.Time{}
+	}
+	return c.snap.TakenAt
+}
+
+// Status implements Adapter.Status.
+func (c *CachedAdapter) Status(ctx context.Context) (*TownStatus, error) {
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := *snap.Status
+	return &status, nil
+}
+
+// Town implements Adapter.Town.
+func (c *CachedAdapter) Town(ctx context.Context) (*Town, error) {
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Err != nil {
+		return nil, snap.Err
+	}
+	return snap.Town, nil
+}
+
+// Rigs implements Adapter.Rigs.
+func (c *CachedAdapter) Rigs(ctx context.Context) ([]Rig, error) {
+	town, err := c.Town(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return town.Rigs, nil
+}
+
+// Rig implements Adapter.Rig.
+func (c *CachedAdapter) Rig(ctx context.Context, name string) (*Rig, error) {
+	rigs, err := c.Rigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rig := range rigs {
+		if rig.Name == name {
+			return &rig, nil
+		}
+	}
+
+	return nil, &RigNotFoundError{Name: name}
+}
+
+// Agents implements Adapter.Agents.
+func (c *CachedAdapter) Agents(ctx context.Context) ([]Agent, error) {
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Err != nil {
+		return nil, snap.Err
+	}
+	return snap.Agents, nil
+}
+
+// Agent implements Adapter.Agent.
+func (c *CachedAdapter) Agent(ctx context.Context, address string) (*Agent, error) {
+	agents, err := c.Agents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, agent := range agents {
+		if agent.Address() == address {
+			return &agent, nil
+		}
+	}
+
+	return nil, &NotFoundError{Kind: "agent", ID: address}
+}
+
+// Pane implements Adapter.Pane. Captures are never cached.
+func (c *CachedAdapter) Pane(ctx context.Context, agent *Agent, lines int, ansi bool) (*Pane, error) {
+	return c.source.Pane(ctx, agent, lines, ansi)
+}
+
+// Convoys implements Adapter.Convoys.
+func (c *CachedAdapter) Convoys(ctx context.Context) ([]Convoy, error) {
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Err != nil {
+		return nil, snap.Err
+	}
+	if snap.ConvoysErr != nil {
+		return nil, snap.ConvoysErr
+	}
+	return snap.Town.Convoys, nil
+}
+
+// Convoy implements Adapter.Convoy.
+func (c *CachedAdapter) Convoy(ctx context.Context, id string) (*Convoy, error) {
+	convoys, err := c.Convoys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, convoy := range convoys {
+		if convoy.ID == id {
+			return &convoy, nil
+		}
+	}
+
+	return nil, &NotFoundError{Kind: "convoy", ID: id}
+}
+
+// Molecules implements Adapter.Molecules.
+func (c *CachedAdapter) Molecules(ctx context.Context) ([]Molecule, error) {
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Err != nil {
+		return nil, snap.Err
+	}
+	return snap.Molecules, nil
+}
+
+// Molecule implements Adapter.Molecule.
+func (c *CachedAdapter) Molecule(ctx context.Context, id string) (*Molecule, error) {
+	molecules, err := c.Molecules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mol := range molecules {
+		if mol.ID == id {
+			return &mol, nil
+		}
+	}
+
+	return nil, &NotFoundError{Kind: "molecule", ID: id}
+}
+
+// Mail implements Adapter.Mail. Mail is always read live.
+func (c *CachedAdapter) Mail(ctx context.Context, address string) ([]Message, error) {
+	return c.source.Mail(ctx, address)
+}
+
+// TownMail implements Adapter.TownMail from the snapshot.
+func (c *CachedAdapter) TownMail(ctx context.Context) ([]Message, error) {
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Err != nil {
+		return nil, snap.Err
+	}
+	if snap.MailErr != nil {
+		return nil, snap.MailErr
+	}
+	return snap.Mail, nil
+}
+
+// MergeQueue implements Adapter.MergeQueue. Conflict checks are always live.
+func (c *CachedAdapter) MergeQueue(ctx context.Context, rig string) (*MergeQueue, error) {
+	return c.source.MergeQueue(ctx, rig)
+}
+
+// snapshot scans the whole town once.
+func (a *FSAdapter) snapshot(ctx context.Context) *Snapshot {
+	snap := &Snapshot{TakenAt: time.Now()}
+
+	if !a.townExists() {
+		snap.Err = &TownNotFoundError{Root: a.townRoot}
+		snap.Status = newTownStatus(a.townRoot, nil, fmt.Errorf("Town not found at %s", a.townRoot))
+		return snap
+	}
+
+	scan, err := a.scanTown(ctx)
+	if err != nil {
+		snap.Status = newTownStatus(a.townRoot, nil, err)
+		snap.Err = err
+		return snap
+	}
+
+	town := scan.town
+	snap.Status = newTownStatus(a.townRoot, town, nil)
+	snap.Town = town
+	snap.ConvoysErr = scan.convoysErr
+	snap.Agents = townAgents(town)
+	snap.Molecules = a.agentMolecules(snap.Agents)
+
+	// Unread counts come from every inbox; agents whose inbox could not
+	// be read are marked unknown rather than dropping everyone's counts
+	var unread map[string]int
+	snap.Mail, unread, snap.MailErr = a.agentMail(ctx, snap.Agents)
+	setUnreadMail(town, unread)
+	snap.Agents = townAgents(town)
+	return snap
+}
+
+// fingerprint summarizes modification times of the parts of the town tree
+// that affect a scan: rig and agent directories and agent state files.
+func (a *FSAdapter) fingerprint() string {
+	var b strings.Builder
+
+	stamp := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			b.WriteString(path)
+			b.WriteByte('=')
+			b.WriteString(strconv.FormatInt(info.ModTime().UnixNano(), 10))
+			b.WriteByte(';')
+		}
+	}
+	stampAgent := func(dir string) {
+		stamp(filepath.Join(dir, ".claude", "hook.json"))
+		stamp(filepath.Join(dir, ".claude", "seance.json"))
+		stamp(filepath.Join(dir, ".beads", "molecule.json"))
+	}
+
+	stamp(a.townRoot)
+	stampAgent(filepath.Join(a.townRoot, "mayor"))
+
+	entries, err := os.ReadDir(a.townRoot)
+	if err != nil {
+		return b.String()
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		rigPath := filepath.Join(a.townRoot, entry.Name())
+		stamp(rigPath)
+		stampAgent(filepath.Join(rigPath, "witness"))
+		stampAgent(filepath.Join(rigPath, "refinery"))
+
+		for _, group := range []string{"polecats", "crew"} {
+			groupDir := filepath.Join(rigPath, group)
+			stamp(groupDir)
+			agents, err := os.ReadDir(groupDir)
+			if err != nil {
+				continue
+			}
+			for _, agent := range agents {
+				if agent.IsDir() {
+					stampAgent(filepath.Join(groupDir, agent.Name()))
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// setUnreadMail records unread counts on every agent in the town. Agents
+// missing from unread are marked as having an unknown count.
+func setUnreadMail(town *Town, unread map[string]int) {
+	set := func(agent *Agent) {
+		if agent != nil {
+			count, ok := unread[agent.Address()]
+			agent.UnreadMail = count
+			agent.MailUnknown = !ok
+		}
+	}
+
+	set(town.Mayor)
+	set(town.Deacon)
+	for i := range town.Rigs {
+		rig := &town.Rigs[i]
+		set(rig.Witness)
+		set(rig.Refinery)
+		for j := range rig.Polecats {
+			set(&rig.Polecats[j])
+		}
+		for j := range rig.Crew {
+			set(&rig.Crew[j])
+		}
+	}
+}
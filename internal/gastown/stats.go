@@ -0,0 +1,185 @@
+package gastown
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// slowestStepsLimit caps the number of steps reported in FormulaStats.Slowest.
+const slowestStepsLimit = 5
+
+// StepStats aggregates timing for one step ID across molecules of a formula.
+type StepStats struct {
+	StepID                string  `json:"step_id"`
+	Index                 int     `json:"index"`
+	Description           string  `json:"description,omitempty"`
+	Samples               int     `json:"samples"`
+	P50Seconds            float64 `json:"p50_seconds"`
+	P90Seconds            float64 `json:"p90_seconds"`
+	MaxSeconds            float64 `json:"max_seconds"`
+	WaitP50Seconds        float64 `json:"wait_p50_seconds"`
+	WaitP90Seconds        float64 `json:"wait_p90_seconds"`
+	Running               int     `json:"running"`
+	LongestRunningSeconds float64 `json:"longest_running_seconds,omitempty"`
+}
+
+// FormulaStats summarizes step timing for all molecules using a formula.
+type FormulaStats struct {
+	Formula   string      `json:"formula"`
+	Molecules int         `json:"molecules"`
+	Steps     []StepStats `json:"steps"`
+	Slowest   []StepStats `json:"slowest"`
+}
+
+// ComputeStepTimings fills in DurationSeconds and WaitSeconds for each step.
+// Duration is the time from StartedAt to CompletedAt. Wait is the time a step
+// spent ready but not started: from the completion of its last dependency (or
+// the previous step, or the molecule's creation) until StartedAt.
+func ComputeStepTimings(mol *Molecule) {
+	completed := make(map[string]time.Time)
+	for _, step := range mol.Steps {
+		if step.CompletedAt != nil {
+			completed[step.ID] = *step.CompletedAt
+		}
+	}
+
+	for i := range mol.Steps {
+		step := &mol.Steps[i]
+		step.DurationSeconds = 0
+		step.WaitSeconds = 0
+
+		if step.StartedAt == nil {
+			continue
+		}
+		if step.CompletedAt != nil && step.CompletedAt.After(*step.StartedAt) {
+			step.DurationSeconds = step.CompletedAt.Sub(*step.StartedAt).Seconds()
+		}
+
+		var ready time.Time
+		switch {
+		case len(step.Needs) > 0:
+			for _, need := range step.Needs {
+				if t, ok := completed[need]; ok && t.After(ready) {
+					ready = t
+				}
+			}
+		case i > 0 && mol.Steps[i-1].CompletedAt != nil:
+			ready = *mol.Steps[i-1].CompletedAt
+		default:
+			ready = mol.CreatedAt
+		}
+
+		if !ready.IsZero() && step.StartedAt.After(ready) {
+			step.WaitSeconds = step.StartedAt.Sub(ready).Seconds()
+		}
+	}
+}
+
+// ComputeFormulaStats aggregates step durations and wait times per step ID
+// across all molecules created from the given formula. Steps that have started
+// but not completed are counted as running, measured against now.
+func ComputeFormulaStats(formula string, molecules []Molecule, now time.Time) *FormulaStats {
+	stats := &FormulaStats{
+		Formula: formula,
+		Steps:   []StepStats{},
+		Slowest: []StepStats{},
+	}
+
+	type samples struct {
+		stats     StepStats
+		durations []float64
+		waits     []float64
+	}
+	byStep := make(map[string]*samples)
+	var order []string
+
+	for _, mol := range molecules {
+		if mol.Formula != formula {
+			continue
+		}
+		stats.Molecules++
+
+		mol.Steps = append([]MoleculeStep(nil), mol.Steps...)
+		ComputeStepTimings(&mol)
+
+		for _, step := range mol.Steps {
+			s, ok := byStep[step.ID]
+			if !ok {
+				s = &samples{stats: StepStats{
+					StepID:      step.ID,
+					Index:       step.Index,
+					Description: step.Description,
+				}}
+				byStep[step.ID] = s
+				order = append(order, step.ID)
+			}
+
+			if step.StartedAt == nil {
+				continue
+			}
+			if step.CompletedAt == nil {
+				s.stats.Running++
+				if running := now.Sub(*step.StartedAt).Seconds(); running > s.stats.LongestRunningSeconds {
+					s.stats.LongestRunningSeconds = running
+				}
+			} else {
+				s.durations = append(s.durations, step.DurationSeconds)
+			}
+			s.waits = append(s.waits, step.WaitSeconds)
+		}
+	}
+
+	for _, id := range order {
+		s := byStep[id]
+		s.stats.Samples = len(s.durations)
+		s.stats.P50Seconds = percentile(s.durations, 50)
+		s.stats.P90Seconds = percentile(s.durations, 90)
+		s.stats.MaxSeconds = percentile(s.durations, 100)
+		s.stats.WaitP50Seconds = percentile(s.waits, 50)
+		s.stats.WaitP90Seconds = percentile(s.waits, 90)
+		stats.Steps = append(stats.Steps, s.stats)
+	}
+
+	sort.SliceStable(stats.Steps, func(i, j int) bool {
+		return stats.Steps[i].Index < stats.Steps[j].Index
+	})
+
+	// Slowest steps ranked by p90 duration, then by the longest running instance
+	for _, step := range stats.Steps {
+		if step.Samples > 0 || step.Running > 0 {
+			stats.Slowest = append(stats.Slowest, step)
+		}
+	}
+	sort.SliceStable(stats.Slowest, func(i, j int) bool {
+		a, b := stats.Slowest[i], stats.Slowest[j]
+		if a.P90Seconds != b.P90Seconds {
+			return a.P90Seconds > b.P90Seconds
+		}
+		return a.LongestRunningSeconds > b.LongestRunningSeconds
+	})
+	if len(stats.Slowest) > slowestStepsLimit {
+		stats.Slowest = stats.Slowest[:slowestStepsLimit]
+	}
+
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of values.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,111 @@
+package gastown
+
+import (
+	"testing"
+	"time"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestComputeStepTimings(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mol := Molecule{
+		ID:        "mol-1",
+		CreatedAt: base,
+		Steps: []MoleculeStep{
+			{Index: 0, ID: "design", StartedAt: timePtr(base.Add(1 * time.Minute)), CompletedAt: timePtr(base.Add(11 * time.Minute))},
+			{Index: 1, ID: "implement", Needs: []string{"design"}, StartedAt: timePtr(base.Add(15 * time.Minute)), CompletedAt: timePtr(base.Add(45 * time.Minute))},
+			{Index: 2, ID: "review", StartedAt: timePtr(base.Add(50 * time.Minute))},
+		},
+	}
+
+	ComputeStepTimings(&mol)
+
+	tests := []struct {
+		step     string
+		duration float64
+		wait     float64
+	}{
+		{"design", 600, 60},
+		{"implement", 1800, 240},
+		{"review", 0, 300},
+	}
+
+	for i, tt := range tests {
+		step := mol.Steps[i]
+		if step.DurationSeconds != tt.duration {
+			t.Errorf("%s: expected duration %v, got %v", tt.step, tt.duration, step.DurationSeconds)
+		}
+		if step.WaitSeconds != tt.wait {
+			t.Errorf("%s: expected wait %v, got %v", tt.step, tt.wait, step.WaitSeconds)
+		}
+	}
+}
+
+func TestComputeFormulaStats(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := base.Add(2 * time.Hour)
+
+	newMol := func(id, formula string, buildMinutes int, testStarted bool) Molecule {
+		mol := Molecule{
+			ID:        id,
+			Formula:   formula,
+			CreatedAt: base,
+			Steps: []MoleculeStep{
+				{Index: 0, ID: "build", StartedAt: timePtr(base), CompletedAt: timePtr(base.Add(time.Duration(buildMinutes) * time.Minute))},
+				{Index: 1, ID: "test"},
+			},
+		}
+		if testStarted {
+			mol.Steps[1].StartedAt = timePtr(base.Add(time.Hour))
+		}
+		return mol
+	}
+
+	molecules := []Molecule{
+		newMol("mol-1", "polecat-work", 10, false),
+		newMol("mol-2", "polecat-work", 20, true),
+		newMol("mol-3", "polecat-work", 30, false),
+		newMol("mol-4", "other", 90, false),
+	}
+
+	stats := ComputeFormulaStats("polecat-work", molecules, now)
+
+	if stats.Molecules != 3 {
+		t.Fatalf("expected 3 molecules, got %d", stats.Molecules)
+	}
+	if len(stats.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(stats.Steps))
+	}
+
+	build := stats.Steps[0]
+	if build.StepID != "build" || build.Samples != 3 {
+		t.Fatalf("unexpected build stats: %+v", build)
+	}
+	if build.P50Seconds != 1200 {
+		t.Errorf("expected build p50 1200s, got %v", build.P50Seconds)
+	}
+	if build.P90Seconds != 1800 {
+		t.Errorf("expected build p90 1800s, got %v", build.P90Seconds)
+	}
+
+	test := stats.Steps[1]
+	if test.Running != 1 {
+		t.Errorf("expected 1 running test step, got %d", test.Running)
+	}
+	if test.LongestRunningSeconds != 3600 {
+		t.Errorf("expected longest running 3600s, got %v", test.LongestRunningSeconds)
+	}
+
+	if len(stats.Slowest) == 0 || stats.Slowest[0].StepID != "build" {
+		t.Errorf("expected build to be the slowest step, got %+v", stats.Slowest)
+	}
+
+	// Input molecules must not be modified
+	if molecules[0].Steps[0].DurationSeconds != 0 {
+		t.Error("ComputeFormulaStats modified its input")
+	}
+}
@@ -56,16 +56,21 @@ type Rig struct {
 
 // Agent represents a Gas Town agent (polecat, witness, etc.).
 type Agent struct {
-	Role         Role        `json:"role"`
-	Name         string      `json:"name"`
-	Rig          string      `json:"rig,omitempty"`
-	Status       AgentStatus `json:"status"`
-	Session      string      `json:"session,omitempty"`
-	Molecule     string      `json:"molecule,omitempty"`
-	HookAttached bool        `json:"hook_attached,omitempty"`
-	LastActive   time.Time   `json:"last_active,omitempty"`
-	Compaction   int         `json:"compaction,omitempty"`
-	WorkDir      string      `json:"work_dir,omitempty"`
+	Role           Role        `json:"role"`
+	Name           string      `json:"name"`
+	Rig            string      `json:"rig,omitempty"`
+	Status         AgentStatus `json:"status"`
+	StatusReason   string      `json:"status_reason,omitempty"`
+	Session        string      `json:"session,omitempty"`
+	Molecule       string      `json:"molecule,omitempty"`
+	HookAttached   bool        `json:"hook_attached,omitempty"`
+	LastActive     time.Time   `json:"last_active,omitempty"`
+	ActivitySource string      `json:"activity_source,omitempty"`
+	Compaction     int         `json:"compaction,omitempty"`
+	WorkDir        string      `json:"work_dir,omitempty"`
+	Git            *GitStatus  `json:"git,omitempty"`
+	UnreadMail     int         `json:"unread_mail,omitempty"`
+	MailUnknown    bool        `json:"mail_unknown,omitempty"` // Inbox could not be read, so UnreadMail is not known
 }
 
 // Address returns the mail-style address for this agent.
@@ -129,6 +134,8 @@ type Message struct {
 	Read      bool      `json:"read"`
 	Priority  string    `json:"priority"`
 	Type      string    `json:"type"`
+	ReplyTo   string    `json:"reply_to,omitempty"`
+	ThreadID  string    `json:"thread_id,omitempty"`
 }
 
 // MoleculeStatus represents the status of a molecule.
@@ -160,13 +167,15 @@ type Molecule struct {
 
 // MoleculeStep represents a step in a molecule workflow.
 type MoleculeStep struct {
-	Index       int      `json:"index"`
-	ID          string   `json:"id"`
-	Description string   `json:"description"`
-	Status      string   `json:"status"`
-	Needs       []string `json:"needs,omitempty"`
-	StartedAt   *time.Time `json:"started_at,omitempty"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Index           int        `json:"index"`
+	ID              string     `json:"id"`
+	Description     string     `json:"description"`
+	Status          string     `json:"status"`
+	Needs           []string   `json:"needs,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds,omitempty"`
+	WaitSeconds     float64    `json:"wait_seconds,omitempty"`
 }
 
 // TownStatus provides a summary of town health.
@@ -0,0 +1,132 @@
+// Package logging configures gvid's structured JSON logs and carries the
+// request ID through contexts, so the bd, gt and tmux calls made for a
+// request can be tied back to its access log line.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader is the header a request ID is read from and echoed in.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds client-supplied request IDs.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 16-character hex ID.
+func NewRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID reports whether a client-supplied ID is safe to log and
+// echo: non-empty, bounded and printable ASCII without spaces.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error".
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
+
+// New returns a logger writing JSON lines to w at level and above. Records
+// logged with a context carrying a request ID include it as request_id.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// contextHandler adds the request ID from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle adds request_id, if any, and passes the record on.
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps request IDs on loggers derived with With.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps request IDs on loggers derived with WithGroup.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Command logs one run of an external command with its duration and exit
+// code. Successful runs are logged at debug level; failures at warn with
+// their stderr.
+func Command(ctx context.Context, name string, args []string, elapsed time.Duration, stderr string, err error) {
+	level := slog.LevelDebug
+	attrs := []slog.Attr{
+		slog.String("cmd", name),
+		slog.String("args", strings.Join(args, " ")),
+		slog.Float64("duration_ms", Milliseconds(elapsed)),
+		slog.Int("exit_code", ExitCode(err)),
+	}
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			attrs = append(attrs, slog.String("stderr", stderr))
+		}
+	}
+	slog.LogAttrs(ctx, level, "exec", attrs...)
+}
+
+// ExitCode returns the exit status for err from exec.Cmd.Run: 0 for nil,
+// the process status for an exit error, and -1 when the command did not
+// run or was killed.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// Milliseconds converts d to fractional milliseconds for log fields.
+func Milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
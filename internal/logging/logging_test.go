@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureLogs routes the default logger to a buffer for the test.
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(New(&buf, level))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Log line is not JSON: %q", line)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+
+	ctx := WithRequestID(context.Background(), "abc123")
+	slog.With("component", "test").InfoContext(ctx, "with id")
+	slog.Info("without id")
+
+	records := decodeLines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0]["request_id"] != "abc123" || records[0]["component"] != "test" {
+		t.Errorf("Expected request_id and component, got %v", records[0])
+	}
+	if _, ok := records[1]["request_id"]; ok {
+		t.Errorf("Expected no request_id, got %v", records[1])
+	}
+}
+
+func TestCommand(t *testing.T) {
+	buf := captureLogs(t, slog.LevelDebug)
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	Command(ctx, "bd", []string{"list", "--json"}, 1500*time.Microsecond, "", nil)
+
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	Command(ctx, "gt", []string{"status"}, time.Millisecond, "boom\n", err)
+
+	Command(ctx, "tmux", nil, 0, "", errors.New("exec: \"tmux\": executable file not found"))
+
+	records := decodeLines(t, buf)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	ok := records[0]
+	if ok["level"] != "DEBUG" || ok["cmd"] != "bd" || ok["args"] != "list --json" ||
+		ok["exit_code"] != 0.0 || ok["duration_ms"] != 1.5 || ok["request_id"] != "req-1" {
+		t.Errorf("Unexpected success record %v", ok)
+	}
+
+	failed := records[1]
+	if failed["level"] != "WARN" || failed["exit_code"] != 3.0 || failed["stderr"] != "boom" {
+		t.Errorf("Unexpected failure record %v", failed)
+	}
+
+	if records[2]["exit_code"] != -1.0 {
+		t.Errorf("Expected exit code -1 for a command that did not run, got %v", records[2]["exit_code"])
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := map[string]bool{
+		"":                       false,
+		"abc-123":                true,
+		"has space":              false,
+		"line\nbreak":            false,
+		strings.Repeat("x", 129): false,
+		NewRequestID():           true,
+	}
+	for id, want := range tests {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
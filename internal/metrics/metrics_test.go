@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	reg := NewRegistry()
+
+	requests := reg.NewCounterVec("app_requests_total", "Requests served.", "code")
+	requests.Inc("200")
+	requests.Inc("200")
+	requests.Add(3, "500")
+
+	latency := reg.NewHistogramVec("app_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(5, "/a")
+
+	reg.NewGaugeFunc("app_up", "Whether the app is up.", func(context.Context) []Sample {
+		return []Sample{{Value: 1}}
+	})
+	reg.NewGaugeFunc("app_queue", "Queue depth.", func(context.Context) []Sample {
+		return []Sample{{Labels: []string{`say "hi"`}, Value: 2}}
+	}, "name")
+
+	var b strings.Builder
+	if err := reg.WriteText(context.Background(), &b); err != nil {
+		t.Fatalf("WriteText() returned error: %v", err)
+	}
+
+	want := `# HELP app_latency_seconds Latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{route="/a",le="0.1"} 1
+app_latency_seconds_bucket{route="/a",le="1"} 2
+app_latency_seconds_bucket{route="/a",le="+Inf"} 3
+app_latency_seconds_sum{route="/a"} 5.55
+app_latency_seconds_count{route="/a"} 3
+# HELP app_queue Queue depth.
+# TYPE app_queue gauge
+app_queue{name="say \"hi\""} 2
+# HELP app_requests_total Requests served.
+# TYPE app_requests_total counter
+app_requests_total{code="200"} 2
+app_requests_total{code="500"} 3
+# HELP app_up Whether the app is up.
+# TYPE app_up gauge
+app_up 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
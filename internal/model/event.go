@@ -10,6 +10,8 @@ const (
 	EventTypeIssueUpdated EventType = "issue_updated"
 	EventTypeIssueDeleted EventType = "issue_deleted"
 	EventTypeHeartbeat    EventType = "heartbeat"
+	EventTypeAgentStatus  EventType = "agent_status"
+	EventTypeShutdown     EventType = "shutdown"
 )
 
 // Event is the base type for all SSE events.
@@ -41,6 +43,20 @@ type IssueDeletedEvent struct {
 	DeletedAt time.Time `json:"deleted_at"`
 }
 
+// AgentStatusEvent is sent when a Gas Town agent's status changes.
+type AgentStatusEvent struct {
+	Address        string    `json:"address"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+// ShutdownEvent is the last event sent before the daemon stops.
+type ShutdownEvent struct {
+	Message string `json:"message"`
+}
+
 // HeartbeatEvent is sent periodically to keep the connection alive.
 type HeartbeatEvent struct {
 	Timestamp time.Time `json:"timestamp"`
@@ -85,3 +101,28 @@ func NewIssueUpdatedEvent(id string, status, previousStatus Status) Event {
 		Timestamp: now,
 	}
 }
+
+// NewAgentStatusEvent creates an agent_status event.
+func NewAgentStatusEvent(address, status, previousStatus, reason string) Event {
+	now := time.Now()
+	return Event{
+		Type: EventTypeAgentStatus,
+		Data: AgentStatusEvent{
+			Address:        address,
+			Status:         status,
+			PreviousStatus: previousStatus,
+			Reason:         reason,
+			ChangedAt:      now,
+		},
+		Timestamp: now,
+	}
+}
+
+// NewShutdownEvent creates a shutdown event.
+func NewShutdownEvent() Event {
+	return Event{
+		Type:      EventTypeShutdown,
+		Data:      ShutdownEvent{Message: "Gastown Viewer Intent is shutting down"},
+		Timestamp: time.Now(),
+	}
+}
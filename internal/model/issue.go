@@ -37,6 +37,8 @@ type Issue struct {
 	Description string         `json:"description,omitempty"`
 	Status      Status         `json:"status"`
 	Priority    Priority       `json:"priority"`
+	Type        string         `json:"type,omitempty"`
+	Assignee    string         `json:"assignee,omitempty"`
 	Parent      *IssueSummary  `json:"parent,omitempty"`
 	Children    []IssueSummary `json:"children"`
 	Blocks      []IssueSummary `json:"blocks"`
@@ -44,6 +46,7 @@ type Issue struct {
 	DoneWhen    []string       `json:"done_when,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	ClosedAt    *time.Time     `json:"closed_at,omitempty"`
 }
 
 // IssueListResponse is the response for GET /api/v1/issues.
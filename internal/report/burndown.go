@@ -0,0 +1,150 @@
+// Package report computes progress reports such as burndown and cumulative
+// flow from issue timestamps and recorded board history.
+package report
+
+import (
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+// maxPoints bounds how many points a report computes.
+const maxPoints = 500
+
+// BurndownPoint is the scope and remaining work at one time.
+type BurndownPoint struct {
+	At        time.Time `json:"at"`
+	Total     int       `json:"total"`
+	Done      int       `json:"done"`
+	Remaining int       `json:"remaining"`
+	Ideal     float64   `json:"ideal"`
+}
+
+// Burndown tracks remaining work for a set of issues over time.
+type Burndown struct {
+	Scope     string          `json:"scope"` // "epic" or "convoy"
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Step      string          `json:"step"`
+	Total     int             `json:"total"`
+	Done      int             `json:"done"`
+	Remaining int             `json:"remaining"`
+	Issues    []string        `json:"issues"`
+	Points    []BurndownPoint `json:"points"`
+}
+
+// ClosedAt returns when an issue was closed, or nil if it is open. Done
+// issues without a recorded close time count as closed at their last update.
+func ClosedAt(issue model.Issue) *time.Time {
+	if issue.ClosedAt != nil {
+		return issue.ClosedAt
+	}
+	if issue.Status == model.StatusDone {
+		t := issue.UpdatedAt
+		return &t
+	}
+	return nil
+}
+
+// EarliestCreated returns the earliest creation time among issues.
+func EarliestCreated(issues []model.Issue) time.Time {
+	var earliest time.Time
+	for _, issue := range issues {
+		if !issue.CreatedAt.IsZero() && (earliest.IsZero() || issue.CreatedAt.Before(earliest)) {
+			earliest = issue.CreatedAt
+		}
+	}
+	return earliest
+}
+
+// DefaultStep picks a step giving roughly 30 points over the range, rounded
+// to a whole hour or day.
+func DefaultStep(from, to time.Time) time.Duration {
+	step := to.Sub(from) / 30
+	switch {
+	case step <= time.Hour:
+		return time.Hour
+	case step < 24*time.Hour:
+		return step.Round(time.Hour)
+	default:
+		return step.Round(24 * time.Hour)
+	}
+}
+
+// ComputeBurndown samples total, done and remaining issue counts every step
+// from from to to. Scope grows as issues are created; the ideal line falls
+// linearly from the remaining count at from to zero at to.
+func ComputeBurndown(scope, id, title string, issues []model.Issue, from, to time.Time, step time.Duration) *Burndown {
+	step = Step(from, to, step)
+
+	bd := &Burndown{
+		Scope:  scope,
+		ID:     id,
+		Title:  title,
+		From:   from,
+		To:     to,
+		Step:   step.String(),
+		Issues: make([]string, 0, len(issues)),
+		Points: []BurndownPoint{},
+	}
+	for _, issue := range issues {
+		bd.Issues = append(bd.Issues, issue.ID)
+	}
+
+	var startRemaining int
+	span := to.Sub(from).Seconds()
+	for _, at := range steps(from, to, step) {
+		p := BurndownPoint{At: at}
+		for _, issue := range issues {
+			if issue.CreatedAt.After(at) {
+				continue
+			}
+			p.Total++
+			if closed := ClosedAt(issue); closed != nil && !closed.After(at) {
+				p.Done++
+			}
+		}
+		p.Remaining = p.Total - p.Done
+
+		if len(bd.Points) == 0 {
+			startRemaining = p.Remaining
+		}
+		if span > 0 {
+			p.Ideal = float64(startRemaining) * (1 - at.Sub(from).Seconds()/span)
+		}
+		bd.Points = append(bd.Points, p)
+	}
+
+	if n := len(bd.Points); n > 0 {
+		last := bd.Points[n-1]
+		bd.Total, bd.Done, bd.Remaining = last.Total, last.Done, last.Remaining
+	}
+
+	return bd
+}
+
+// Step returns the step a report over the range uses: step if positive,
+// otherwise DefaultStep, widened if needed to stay within maxPoints.
+func Step(from, to time.Time, step time.Duration) time.Duration {
+	if step <= 0 {
+		step = DefaultStep(from, to)
+	}
+	if floor := to.Sub(from) / maxPoints; step < floor {
+		step = floor
+	}
+	if step <= 0 {
+		step = time.Hour
+	}
+	return step
+}
+
+// steps returns times from from to to every step, always ending at to.
+func steps(from, to time.Time, step time.Duration) []time.Time {
+	var times []time.Time
+	for at := from; at.Before(to); at = at.Add(step) {
+		times = append(times, at)
+	}
+	return append(times, to)
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+// CFDPoint is the number of issues in each board column at one time.
+type CFDPoint struct {
+	At     time.Time            `json:"at"`
+	Counts map[model.Status]int `json:"counts"`
+	Source string               `json:"source"` // "samples" or "issues"
+}
+
+// CumulativeFlow is a cumulative flow diagram across the board columns.
+type CumulativeFlow struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Step    string         `json:"step"`
+	Columns []model.Status `json:"columns"`
+	Points  []CFDPoint     `json:"points"`
+}
+
+// SampleFunc returns the recorded count of a board column averaged over
+// [at, at+step), and false if nothing was recorded then.
+type SampleFunc func(status model.Status, at time.Time, step time.Duration) (float64, bool)
+
+// Columns returns the board column statuses in board order.
+func Columns() []model.Status {
+	board := model.NewBoard()
+	columns := make([]model.Status, 0, len(board.Columns))
+	for _, col := range board.Columns {
+		columns = append(columns, col.Status)
+	}
+	return columns
+}
+
+// ComputeCumulativeFlow builds a cumulative flow diagram. Column counts come
+// from recorded board samples where available; before sampling began they
+// are reconstructed from issue creation and close times, which only
+// distinguishes open (pending) from done work.
+func ComputeCumulativeFlow(issues []model.Issue, samples SampleFunc, from, to time.Time, step time.Duration) *CumulativeFlow {
+	step = Step(from, to, step)
+	columns := Columns()
+
+	cfd := &CumulativeFlow{
+		From:    from,
+		To:      to,
+		Step:    step.String(),
+		Columns: columns,
+		Points:  []CFDPoint{},
+	}
+
+	for _, at := range steps(from, to, step) {
+		p := CFDPoint{At: at, Counts: make(map[model.Status]int, len(columns))}
+
+		sampled := false
+		if samples != nil {
+			for _, status := range columns {
+				if v, ok := samples(status, at, step); ok {
+					p.Counts[status] = int(v + 0.5)
+					sampled = true
+				}
+			}
+		}
+
+		if sampled {
+			p.Source = "samples"
+		} else {
+			p.Source = "issues"
+			for _, status := range columns {
+				p.Counts[status] = 0
+			}
+			for _, issue := range issues {
+				if issue.CreatedAt.After(at) {
+					continue
+				}
+				if closed := ClosedAt(issue); closed != nil && !closed.After(at) {
+					p.Counts[model.StatusDone]++
+				} else {
+					p.Counts[model.StatusPending]++
+				}
+			}
+		}
+
+		cfd.Points = append(cfd.Points, p)
+	}
+
+	return cfd
+}
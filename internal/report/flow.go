@@ -0,0 +1,177 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+// DurationStats summarizes a set of durations in seconds.
+type DurationStats struct {
+	Count       int     `json:"count"`
+	MeanSeconds float64 `json:"mean_seconds"`
+	P50Seconds  float64 `json:"p50_seconds"`
+	P85Seconds  float64 `json:"p85_seconds"`
+	P95Seconds  float64 `json:"p95_seconds"`
+	MaxSeconds  float64 `json:"max_seconds"`
+}
+
+// FlowGroup is lead and cycle time for one value of a breakdown dimension.
+type FlowGroup struct {
+	Key       string        `json:"key"`
+	Closed    int           `json:"closed"`
+	LeadTime  DurationStats `json:"lead_time"`
+	CycleTime DurationStats `json:"cycle_time"`
+}
+
+// WeekThroughput is the number of issues closed in a week starting Monday.
+type WeekThroughput struct {
+	WeekStart time.Time `json:"week_start"`
+	Closed    int       `json:"closed"`
+}
+
+// Flow reports lead time, cycle time and throughput for issues closed in a
+// time range.
+type Flow struct {
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Closed     int              `json:"closed"`
+	LeadTime   DurationStats    `json:"lead_time"`
+	CycleTime  DurationStats    `json:"cycle_time"`
+	Throughput []WeekThroughput `json:"throughput"`
+	ByType     []FlowGroup      `json:"by_type"`
+	ByPriority []FlowGroup      `json:"by_priority"`
+	ByAssignee []FlowGroup      `json:"by_assignee"`
+}
+
+// flowSample is one closed issue's lead and, if known, cycle time.
+type flowSample struct {
+	issue    model.Issue
+	closed   time.Time
+	lead     float64
+	cycle    float64
+	hasCycle bool
+}
+
+// ComputeFlow computes flow metrics for issues closed between from and to.
+// Lead time runs from creation to close; cycle time runs from started, the
+// first time each issue entered in_progress, to close, and is omitted for
+// issues never seen in progress.
+func ComputeFlow(issues []model.Issue, started map[string]time.Time, from, to time.Time) *Flow {
+	flow := &Flow{
+		From:       from,
+		To:         to,
+		Throughput: []WeekThroughput{},
+	}
+
+	var samples []flowSample
+	for _, issue := range issues {
+		closed := ClosedAt(issue)
+		if closed == nil || closed.Before(from) || closed.After(to) {
+			continue
+		}
+
+		s := flowSample{
+			issue:  issue,
+			closed: *closed,
+			lead:   closed.Sub(issue.CreatedAt).Seconds(),
+		}
+		if start, ok := started[issue.ID]; ok && !start.After(*closed) {
+			s.cycle = closed.Sub(start).Seconds()
+			s.hasCycle = true
+		}
+		samples = append(samples, s)
+	}
+
+	flow.Closed = len(samples)
+	flow.LeadTime, flow.CycleTime = summarize(samples)
+
+	weeks := make(map[time.Time]int)
+	for _, s := range samples {
+		weeks[weekStart(s.closed)]++
+	}
+	for week := weekStart(from); !week.After(to); week = week.AddDate(0, 0, 7) {
+		flow.Throughput = append(flow.Throughput, WeekThroughput{WeekStart: week, Closed: weeks[week]})
+	}
+
+	flow.ByType = groupBy(samples, func(i model.Issue) string { return orDefault(i.Type, "unknown") })
+	flow.ByPriority = groupBy(samples, func(i model.Issue) string { return orDefault(string(i.Priority), "unknown") })
+	flow.ByAssignee = groupBy(samples, func(i model.Issue) string { return orDefault(i.Assignee, "unassigned") })
+
+	return flow
+}
+
+func groupBy(samples []flowSample, key func(model.Issue) string) []FlowGroup {
+	grouped := make(map[string][]flowSample)
+	for _, s := range samples {
+		k := key(s.issue)
+		grouped[k] = append(grouped[k], s)
+	}
+
+	groups := make([]FlowGroup, 0, len(grouped))
+	for k, group := range grouped {
+		lead, cycle := summarize(group)
+		groups = append(groups, FlowGroup{Key: k, Closed: len(group), LeadTime: lead, CycleTime: cycle})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func summarize(samples []flowSample) (lead, cycle DurationStats) {
+	var leads, cycles []float64
+	for _, s := range samples {
+		leads = append(leads, s.lead)
+		if s.hasCycle {
+			cycles = append(cycles, s.cycle)
+		}
+	}
+	return durationStats(leads), durationStats(cycles)
+}
+
+func durationStats(values []float64) DurationStats {
+	stats := DurationStats{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	stats.MeanSeconds = sum / float64(len(sorted))
+	stats.P50Seconds = percentile(sorted, 50)
+	stats.P85Seconds = percentile(sorted, 85)
+	stats.P95Seconds = percentile(sorted, 95)
+	stats.MaxSeconds = sorted[len(sorted)-1]
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// weekStart returns midnight UTC on the Monday of t's week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
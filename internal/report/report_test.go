@@ -0,0 +1,164 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestComputeBurndown(t *testing.T) {
+	day := 24 * time.Hour
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	issues := []model.Issue{
+		{ID: "epic", Status: model.StatusPending, CreatedAt: base},
+		{ID: "a", Status: model.StatusDone, CreatedAt: base, ClosedAt: timePtr(base.Add(day))},
+		{ID: "b", Status: model.StatusDone, CreatedAt: base, UpdatedAt: base.Add(2 * day)},
+		{ID: "c", Status: model.StatusInProgress, CreatedAt: base.Add(day)},
+	}
+
+	bd := ComputeBurndown("epic", "epic", "Epic", issues, base, base.Add(3*day), day)
+
+	if len(bd.Points) != 4 {
+		t.Fatalf("Expected 4 points, got %d", len(bd.Points))
+	}
+
+	want := []struct{ total, done, remaining int }{
+		{3, 0, 3},
+		{4, 1, 3}, // c added to scope as a closes
+		{4, 2, 2}, // b closed at its last update
+		{4, 2, 2},
+	}
+	for i, w := range want {
+		p := bd.Points[i]
+		if p.Total != w.total || p.Done != w.done || p.Remaining != w.remaining {
+			t.Errorf("Point %d: got total=%d done=%d remaining=%d, want %+v", i, p.Total, p.Done, p.Remaining, w)
+		}
+	}
+
+	if bd.Points[0].Ideal != 3 || bd.Points[3].Ideal != 0 {
+		t.Errorf("Expected ideal line from 3 to 0, got %v to %v", bd.Points[0].Ideal, bd.Points[3].Ideal)
+	}
+	if bd.Remaining != 2 || bd.Total != 4 {
+		t.Errorf("Unexpected totals: %+v", bd)
+	}
+}
+
+func TestComputeCumulativeFlow(t *testing.T) {
+	day := 24 * time.Hour
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	issues := []model.Issue{
+		{ID: "a", Status: model.StatusDone, CreatedAt: base, ClosedAt: timePtr(base.Add(day))},
+		{ID: "b", Status: model.StatusPending, CreatedAt: base},
+	}
+
+	// Samples exist only for the last day
+	samples := func(status model.Status, at time.Time, step time.Duration) (float64, bool) {
+		if at.Before(base.Add(2 * day)) {
+			return 0, false
+		}
+		if status == model.StatusInProgress {
+			return 1, true
+		}
+		return 0, true
+	}
+
+	cfd := ComputeCumulativeFlow(issues, samples, base, base.Add(2*day), day)
+	if len(cfd.Points) != 3 {
+		t.Fatalf("Expected 3 points, got %d", len(cfd.Points))
+	}
+
+	first := cfd.Points[0]
+	if first.Source != "issues" || first.Counts[model.StatusPending] != 2 || first.Counts[model.StatusDone] != 0 {
+		t.Errorf("Unexpected reconstructed point: %+v", first)
+	}
+	second := cfd.Points[1]
+	if second.Counts[model.StatusPending] != 1 || second.Counts[model.StatusDone] != 1 {
+		t.Errorf("Unexpected reconstructed point: %+v", second)
+	}
+	last := cfd.Points[2]
+	if last.Source != "samples" || last.Counts[model.StatusInProgress] != 1 {
+		t.Errorf("Unexpected sampled point: %+v", last)
+	}
+}
+
+func TestStep_BoundsPoints(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(365 * 24 * time.Hour)
+
+	if step := Step(from, to, time.Minute); to.Sub(from)/step > maxPoints {
+		t.Errorf("Expected step widened to at most %d points, got %s", maxPoints, step)
+	}
+	if step := Step(from, from.Add(6*time.Hour), 0); step != time.Hour {
+		t.Errorf("Expected default step of 1h for short ranges, got %s", step)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	issues := []model.Issue{{ID: "a", Status: model.StatusPending, CreatedAt: base}}
+
+	bd := ComputeBurndown("convoy", "hq-cv-1", "Auth <v2>", issues, base, base.Add(48*time.Hour), 0)
+	svg := string(BurndownSVG(bd))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "Auth &lt;v2&gt;") {
+		t.Errorf("Expected escaped title in SVG, got %s", svg)
+	}
+
+	cfd := ComputeCumulativeFlow(issues, nil, base, base.Add(48*time.Hour), 0)
+	if svg := string(CumulativeFlowSVG(cfd)); strings.Count(svg, "<polygon") != 4 {
+		t.Errorf("Expected one band per column")
+	}
+}
+
+func TestComputeFlow(t *testing.T) {
+	hour := time.Hour
+	// Monday
+	base := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	issues := []model.Issue{
+		{ID: "a", Type: "bug", Priority: model.PriorityHigh, Assignee: "nux", Status: model.StatusDone,
+			CreatedAt: base, ClosedAt: timePtr(base.Add(10 * hour))},
+		{ID: "b", Type: "task", Priority: model.PriorityHigh, Status: model.StatusDone,
+			CreatedAt: base, ClosedAt: timePtr(base.Add(8 * 24 * hour))},
+		{ID: "c", Type: "bug", Status: model.StatusInProgress, CreatedAt: base},
+		{ID: "old", Status: model.StatusDone, CreatedAt: base.Add(-30 * 24 * hour), ClosedAt: timePtr(base.Add(-20 * 24 * hour))},
+	}
+	started := map[string]time.Time{"a": base.Add(6 * hour)}
+
+	flow := ComputeFlow(issues, started, base, base.Add(14*24*hour))
+
+	if flow.Closed != 2 {
+		t.Fatalf("Expected 2 closed issues in range, got %d", flow.Closed)
+	}
+	if flow.LeadTime.Count != 2 || flow.LeadTime.P50Seconds != 10*3600 {
+		t.Errorf("Unexpected lead time: %+v", flow.LeadTime)
+	}
+	if flow.CycleTime.Count != 1 || flow.CycleTime.MaxSeconds != 4*3600 {
+		t.Errorf("Unexpected cycle time: %+v", flow.CycleTime)
+	}
+
+	if len(flow.Throughput) != 3 || flow.Throughput[0].Closed != 1 || flow.Throughput[1].Closed != 1 {
+		t.Errorf("Unexpected throughput: %+v", flow.Throughput)
+	}
+
+	if len(flow.ByType) != 2 || flow.ByType[0].Key != "bug" || flow.ByType[0].Closed != 1 {
+		t.Errorf("Unexpected type breakdown: %+v", flow.ByType)
+	}
+	if len(flow.ByAssignee) != 2 || flow.ByAssignee[1].Key != "unassigned" {
+		t.Errorf("Unexpected assignee breakdown: %+v", flow.ByAssignee)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if p := percentile(values, 85); p != 9 {
+		t.Errorf("Expected p85=9, got %v", p)
+	}
+	if p := percentile(values, 50); p != 5 {
+		t.Errorf("Expected p50=5, got %v", p)
+	}
+}
@@ -0,0 +1,189 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
+)
+
+// Chart geometry in SVG user units.
+const (
+	chartWidth   = 800
+	chartHeight  = 400
+	marginLeft   = 50
+	marginRight  = 140
+	marginTop    = 40
+	marginBottom = 40
+)
+
+// statusColors match the board column colors used by the web UI.
+var statusColors = map[model.Status]string{
+	model.StatusPending:    "#9ca3af",
+	model.StatusInProgress: "#f59e0b",
+	model.StatusDone:       "#22c55e",
+	model.StatusBlocked:    "#ef4444",
+}
+
+// cfdStackOrder stacks finished work at the bottom of the diagram.
+var cfdStackOrder = []model.Status{
+	model.StatusDone,
+	model.StatusBlocked,
+	model.StatusInProgress,
+	model.StatusPending,
+}
+
+// chart maps times and values onto the plot area.
+type chart struct {
+	buf      bytes.Buffer
+	from, to time.Time
+	maxValue float64
+}
+
+func newChart(title string, from, to time.Time, maxValue float64) *chart {
+	if maxValue <= 0 {
+		maxValue = 1
+	}
+	c := &chart{from: from, to: to, maxValue: maxValue}
+
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&c.buf, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", chartWidth, chartHeight)
+	fmt.Fprintf(&c.buf, `<text x="%d" y="24" font-size="16" font-weight="bold">`, marginLeft)
+	_ = xml.EscapeText(&c.buf, []byte(title))
+	c.buf.WriteString("</text>\n")
+	c.axes()
+	return c
+}
+
+func (c *chart) x(t time.Time) float64 {
+	span := c.to.Sub(c.from).Seconds()
+	width := float64(chartWidth - marginLeft - marginRight)
+	if span <= 0 {
+		return marginLeft
+	}
+	return marginLeft + width*t.Sub(c.from).Seconds()/span
+}
+
+func (c *chart) y(v float64) float64 {
+	height := float64(chartHeight - marginTop - marginBottom)
+	return float64(chartHeight-marginBottom) - height*v/c.maxValue
+}
+
+// axes draws the plot frame with value and date labels.
+func (c *chart) axes() {
+	bottom := chartHeight - marginBottom
+	right := chartWidth - marginRight
+	fmt.Fprintf(&c.buf, `<g stroke="#d1d5db">`+"\n")
+	for i := 0; i <= 4; i++ {
+		y := c.y(c.maxValue * float64(i) / 4)
+		fmt.Fprintf(&c.buf, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f"/>`+"\n", marginLeft, y, right, y)
+	}
+	c.buf.WriteString("</g>\n")
+
+	fmt.Fprintf(&c.buf, `<g fill="#6b7280">`+"\n")
+	for i := 0; i <= 4; i++ {
+		v := c.maxValue * float64(i) / 4
+		fmt.Fprintf(&c.buf, `<text x="%d" y="%.1f" text-anchor="end">%.0f</text>`+"\n", marginLeft-6, c.y(v)+4, v)
+	}
+	layout := "Jan 2"
+	if c.to.Sub(c.from) <= 48*time.Hour {
+		layout = "Jan 2 15:04"
+	}
+	for i := 0; i <= 4; i++ {
+		t := c.from.Add(c.to.Sub(c.from) * time.Duration(i) / 4)
+		fmt.Fprintf(&c.buf, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", c.x(t), bottom+18, t.Format(layout))
+	}
+	c.buf.WriteString("</g>\n")
+}
+
+// legend draws a color key entry at position i.
+func (c *chart) legend(i int, color, label string) {
+	x := chartWidth - marginRight + 16
+	y := marginTop + i*20
+	fmt.Fprintf(&c.buf, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`, x, y, color)
+	fmt.Fprintf(&c.buf, `<text x="%d" y="%d">%s</text>`+"\n", x+18, y+11, label)
+}
+
+func (c *chart) bytes() []byte {
+	c.buf.WriteString("</svg>\n")
+	return c.buf.Bytes()
+}
+
+// BurndownSVG renders a burndown as a line chart of remaining and ideal work.
+func BurndownSVG(bd *Burndown) []byte {
+	maxValue := 0.0
+	for _, p := range bd.Points {
+		if float64(p.Total) > maxValue {
+			maxValue = float64(p.Total)
+		}
+	}
+
+	title := fmt.Sprintf("Burndown: %s %s", bd.Scope, bd.ID)
+	if bd.Title != "" {
+		title += " - " + bd.Title
+	}
+	c := newChart(title, bd.From, bd.To, maxValue)
+
+	line := func(color string, dashed bool, value func(BurndownPoint) float64) {
+		fmt.Fprintf(&c.buf, `<polyline fill="none" stroke="%s" stroke-width="2"`, color)
+		if dashed {
+			c.buf.WriteString(` stroke-dasharray="6 4"`)
+		}
+		c.buf.WriteString(` points="`)
+		for _, p := range bd.Points {
+			fmt.Fprintf(&c.buf, "%.1f,%.1f ", c.x(p.At), c.y(value(p)))
+		}
+		c.buf.WriteString(`"/>` + "\n")
+	}
+
+	line("#9ca3af", false, func(p BurndownPoint) float64 { return float64(p.Total) })
+	line("#6366f1", true, func(p BurndownPoint) float64 { return p.Ideal })
+	line("#ef4444", false, func(p BurndownPoint) float64 { return float64(p.Remaining) })
+
+	c.legend(0, "#ef4444", "remaining")
+	c.legend(1, "#6366f1", "ideal")
+	c.legend(2, "#9ca3af", "scope")
+
+	return c.bytes()
+}
+
+// CumulativeFlowSVG renders a cumulative flow diagram as stacked areas.
+func CumulativeFlowSVG(cfd *CumulativeFlow) []byte {
+	maxValue := 0.0
+	for _, p := range cfd.Points {
+		total := 0
+		for _, n := range p.Counts {
+			total += n
+		}
+		if float64(total) > maxValue {
+			maxValue = float64(total)
+		}
+	}
+
+	c := newChart("Cumulative flow", cfd.From, cfd.To, maxValue)
+
+	base := make([]float64, len(cfd.Points))
+	for i, status := range cfdStackOrder {
+		top := make([]float64, len(cfd.Points))
+		for j, p := range cfd.Points {
+			top[j] = base[j] + float64(p.Counts[status])
+		}
+
+		fmt.Fprintf(&c.buf, `<polygon fill="%s" fill-opacity="0.85" points="`, statusColors[status])
+		for j, p := range cfd.Points {
+			fmt.Fprintf(&c.buf, "%.1f,%.1f ", c.x(p.At), c.y(top[j]))
+		}
+		for j := len(cfd.Points) - 1; j >= 0; j-- {
+			fmt.Fprintf(&c.buf, "%.1f,%.1f ", c.x(cfd.Points[j].At), c.y(base[j]))
+		}
+		c.buf.WriteString(`"/>` + "\n")
+
+		c.legend(len(cfdStackOrder)-1-i, statusColors[status], string(status))
+		base = top
+	}
+
+	return c.bytes()
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// Transition records an agent entering a status.
+type Transition struct {
+	Address  string    `json:"address"`
+	Rig      string    `json:"rig,omitempty"`
+	Role     string    `json:"role,omitempty"`
+	Status   string    `json:"status"`
+	Previous string    `json:"previous,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// Span is a period an agent spent in one status.
+type Span struct {
+	Status          string    `json:"status"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Reason          string    `json:"reason,omitempty"`
+}
+
+// Timeline is an agent's status history over a time range.
+type Timeline struct {
+	Address        string             `json:"address"`
+	From           time.Time          `json:"from"`
+	To             time.Time          `json:"to"`
+	Status         string             `json:"status,omitempty"`
+	Since          time.Time          `json:"since,omitempty"`
+	Spans          []Span             `json:"spans"`
+	TimeInState    map[string]float64 `json:"time_in_state"` // Seconds per status
+	TrackedSeconds float64            `json:"tracked_seconds"`
+	Availability   float64            `json:"availability"` // Fraction of tracked time not offline
+	OfflineCount   int                `json:"offline_count"`
+}
+
+// RecordStatus records t if the agent's status differs from the last one
+// recorded, filling in the previous status. It reports whether t was new.
+func (s *Store) RecordStatus(t Transition) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.history[t.Address]
+	if n := len(history); n > 0 {
+		last := history[n-1]
+		if last.Status == t.Status {
+			return false, nil
+		}
+		t.Previous = last.Status
+	}
+	if t.At.IsZero() {
+		t.At = time.Now()
+	}
+
+	s.history[t.Address] = append(history, t)
+	return true, s.append(historyFile, t)
+}
+
+// Transitions returns the recorded transitions for an address, oldest first.
+func (s *Store) Transitions(address string) []Transition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := s.history[address]
+	result := make([]Transition, len(history))
+	copy(result, history)
+	return result
+}
+
+// Addresses returns every address with recorded history.
+func (s *Store) Addresses() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addresses := make([]string, 0, len(s.history))
+	for address := range s.history {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// StatusUnknown marks spans when gvid was not observing the town, so the
+// agent's status is not known.
+const StatusUnknown = "unknown"
+
+// Timeline returns the agent's status spans between from and to, clipped
+// to the range. Time before the first recorded transition is untracked,
+// and gaps in observation longer than maxGap are unknown.
+func (s *Store) Timeline(address string, maxGap time.Duration, from, to time.Time) *Timeline {
+	return BuildTimeline(address, s.Transitions(address), s.Observed(maxGap), from, to)
+}
+
+// BuildTimeline computes a timeline from transitions sorted oldest first.
+// Time outside the observed intervals is reported as StatusUnknown spans
+// and left out of tracked time; nil observed treats the whole range as
+// observed.
+func BuildTimeline(address string, transitions []Transition, observed []Interval, from, to time.Time) *Timeline {
+	tl := &Timeline{
+		Address:     address,
+		From:        from,
+		To:          to,
+		Spans:       []Span{},
+		TimeInState: make(map[string]float64),
+	}
+
+	if n := len(transitions); n > 0 {
+		tl.Status = transitions[n-1].Status
+		tl.Since = transitions[n-1].At
+	}
+
+	for i, t := range transitions {
+		end := to
+		if i+1 < len(transitions) {
+			end = transitions[i+1].At
+		}
+
+		start := t.At
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		for _, span := range splitObserved(Span{Status: t.Status, Start: start, End: end, Reason: t.Reason}, observed) {
+			span.DurationSeconds = span.End.Sub(span.Start).Seconds()
+			tl.addSpan(span)
+		}
+
+		if t.Status == "offline" && !t.At.Before(from) && t.At.Before(to) && t.Previous != "" {
+			tl.OfflineCount++
+		}
+	}
+
+	if tl.TrackedSeconds > 0 {
+		tl.Availability = (tl.TrackedSeconds - tl.TimeInState["offline"]) / tl.TrackedSeconds
+	}
+
+	return tl
+}
+
+// addSpan appends span, merging adjacent unknown spans, and adds its
+// duration to the totals.
+func (tl *Timeline) addSpan(span Span) {
+	tl.TimeInState[span.Status] += span.DurationSeconds
+	if span.Status != StatusUnknown {
+		tl.TrackedSeconds += span.DurationSeconds
+	}
+
+	if n := len(tl.Spans); n > 0 && span.Status == StatusUnknown {
+		last := &tl.Spans[n-1]
+		if last.Status == StatusUnknown && last.End.Equal(span.Start) {
+			last.End = span.End
+			last.DurationSeconds += span.DurationSeconds
+			return
+		}
+	}
+	tl.Spans = append(tl.Spans, span)
+}
+
+// splitObserved splits span at the edges of the observed intervals,
+// replacing the parts outside them with unknown spans.
+func splitObserved(span Span, observed []Interval) []Span {
+	if observed == nil {
+		return []Span{span}
+	}
+
+	var result []Span
+	unknown := func(start, end time.Time) {
+		if end.After(start) {
+			result = append(result, Span{Status: StatusUnknown, Start: start, End: end})
+		}
+	}
+
+	cursor := span.Start
+	for _, iv := range observed {
+		if !iv.End.After(cursor) {
+			continue
+		}
+		if !iv.Start.Before(span.End) {
+			break
+		}
+
+		start := iv.Start
+		if start.Before(cursor) {
+			start = cursor
+		}
+		end := iv.End
+		if end.After(span.End) {
+			end = span.End
+		}
+		unknown(cursor, start)
+		known := span
+		known.Start, known.End = start, end
+		result = append(result, known)
+		cursor = end
+	}
+	unknown(cursor, span.End)
+
+	return result
+}
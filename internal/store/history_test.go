@@ -0,0 +1,194 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordStatus_PersistsTransitions(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+
+	for i, status := range []string{"active", "active", "stuck", "offline"} {
+		_, err := s.RecordStatus(Transition{
+			Address: "gastown/nux",
+			Rig:     "gastown",
+			Status:  status,
+			At:      base.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("RecordStatus() returned error: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+
+	transitions := reopened.Transitions("gastown/nux")
+	if len(transitions) != 3 {
+		t.Fatalf("Expected repeated status to be dropped, got %d transitions", len(transitions))
+	}
+	if transitions[2].Status != "offline" || transitions[2].Previous != "stuck" {
+		t.Errorf("Unexpected last transition: %+v", transitions[2])
+	}
+}
+
+func TestOpen_SkipsTruncatedLastLine(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"address":"mayor/","status":"active","at":"2026-01-01T12:00:00Z"}` + "\n" + `{"address":"mayor/","sta`
+	if err := os.WriteFile(filepath.Join(dir, historyFile), []byte(content), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	if got := len(s.Transitions("mayor/")); got != 1 {
+		t.Errorf("Expected 1 transition, got %d", got)
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transitions := []Transition{
+		{Address: "gastown/nux", Status: "active", At: base},
+		{Address: "gastown/nux", Status: "offline", Previous: "active", At: base.Add(2 * time.Hour)},
+		{Address: "gastown/nux", Status: "active", Previous: "offline", At: base.Add(3 * time.Hour)},
+	}
+
+	// Range starts mid-way through the first span
+	tl := BuildTimeline("gastown/nux", transitions, nil, base.Add(time.Hour), base.Add(4*time.Hour))
+
+	if len(tl.Spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(tl.Spans))
+	}
+	if !tl.Spans[0].Start.Equal(base.Add(time.Hour)) {
+		t.Errorf("Expected first span clipped to range start, got %s", tl.Spans[0].Start)
+	}
+	if tl.TimeInState["active"] != 2*3600 || tl.TimeInState["offline"] != 3600 {
+		t.Errorf("Unexpected time in state: %v", tl.TimeInState)
+	}
+	if tl.Availability < 0.66 || tl.Availability > 0.67 {
+		t.Errorf("Expected availability 2/3, got %v", tl.Availability)
+	}
+	if tl.OfflineCount != 1 {
+		t.Errorf("Expected 1 offline transition, got %d", tl.OfflineCount)
+	}
+	if tl.Status != "active" || !tl.Since.Equal(base.Add(3*time.Hour)) {
+		t.Errorf("Unexpected current status %s since %s", tl.Status, tl.Since)
+	}
+}
+
+func TestBuildTimeline_Untracked(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transitions := []Transition{{Address: "mayor/", Status: "active", At: base.Add(time.Hour)}}
+
+	tl := BuildTimeline("mayor/", transitions, nil, base, base.Add(2*time.Hour))
+	if tl.TrackedSeconds != 3600 {
+		t.Errorf("Expected only time after first transition tracked, got %v", tl.TrackedSeconds)
+	}
+	if tl.Availability != 1 {
+		t.Errorf("Expected full availability, got %v", tl.Availability)
+	}
+}
+
+func TestBuildTimeline_UnobservedGap(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transitions := []Transition{
+		{Address: "gastown/nux", Status: "active", At: base},
+		{Address: "gastown/nux", Status: "offline", Previous: "active", At: base.Add(3 * time.Hour)},
+	}
+	// gvid ran for the first hour, crashed, and came back at 2h
+	markers := []Marker{
+		{Event: MarkerStart, At: base},
+		{Event: MarkerHeartbeat, At: base.Add(30 * time.Minute)},
+		{Event: MarkerHeartbeat, At: base.Add(time.Hour)},
+		{Event: MarkerStart, At: base.Add(2 * time.Hour)},
+		{Event: MarkerHeartbeat, At: base.Add(150 * time.Minute)},
+		{Event: MarkerHeartbeat, At: base.Add(3 * time.Hour)},
+		{Event: MarkerHeartbeat, At: base.Add(210 * time.Minute)},
+		{Event: MarkerStop, At: base.Add(4 * time.Hour)},
+	}
+	observed := buildIntervals(markers, 2*time.Hour)
+	if len(observed) != 2 || !observed[0].End.Equal(observed[1].Start) {
+		t.Fatalf("Expected a restart within maxGap to leave no gap, got %v", observed)
+	}
+
+	observed = buildIntervals(markers, 30*time.Minute)
+	if len(observed) != 2 || !observed[0].End.Equal(base.Add(90*time.Minute)) {
+		t.Fatalf("Expected crashed run to end maxGap after its last marker, got %v", observed)
+	}
+
+	tl := BuildTimeline("gastown/nux", transitions, observed, base, base.Add(5*time.Hour))
+
+	want := []struct {
+		status string
+		hours  float64
+	}{
+		{"active", 1.5},
+		{StatusUnknown, 0.5},
+		{"active", 1},
+		{"offline", 1},
+		{StatusUnknown, 1},
+	}
+	if len(tl.Spans) != len(want) {
+		t.Fatalf("Expected %d spans, got %+v", len(want), tl.Spans)
+	}
+	for i, w := range want {
+		if tl.Spans[i].Status != w.status || tl.Spans[i].DurationSeconds != w.hours*3600 {
+			t.Errorf("Span %d: expected %s for %vh, got %s for %vs", i, w.status, w.hours, tl.Spans[i].Status, tl.Spans[i].DurationSeconds)
+		}
+	}
+	if tl.TrackedSeconds != 3.5*3600 {
+		t.Errorf("Expected unknown time left out of tracked time, got %v", tl.TrackedSeconds)
+	}
+	if tl.TimeInState[StatusUnknown] != 1.5*3600 {
+		t.Errorf("Expected 1.5h unknown, got %v", tl.TimeInState[StatusUnknown])
+	}
+	if tl.Availability < 0.71 || tl.Availability > 0.72 {
+		t.Errorf("Expected availability 2.5/3.5, got %v", tl.Availability)
+	}
+}
+
+func TestRecordMarker_Persists(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if s.Observed(time.Minute) != nil {
+		t.Error("Expected nil coverage before any marker")
+	}
+	for _, m := range []Marker{{MarkerStart, base}, {MarkerStop, base.Add(time.Hour)}} {
+		if err := s.RecordMarker(m.Event, m.At); err != nil {
+			t.Fatalf("RecordMarker failed: %v", err)
+		}
+	}
+	s.Close()
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer s.Close()
+
+	observed := s.Observed(2 * time.Hour)
+	if len(observed) != 1 || !observed[0].Start.Equal(base) || !observed[0].End.Equal(base.Add(time.Hour)) {
+		t.Errorf("Expected one observed hour after reopen, got %v", observed)
+	}
+}
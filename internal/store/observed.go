@@ -0,0 +1,112 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// observedFile is the append-only log of when gvid was watching the town.
+const observedFile = "observed.jsonl"
+
+// Marker events bracket the periods gvid was running.
+const (
+	MarkerStart     = "start"
+	MarkerHeartbeat = "heartbeat"
+	MarkerStop      = "stop"
+)
+
+// Marker records gvid starting, still running, or stopping at a time.
+type Marker struct {
+	Event string    `json:"event"`
+	At    time.Time `json:"at"`
+}
+
+// Interval is a period gvid was observing the town.
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// loadMarkers reads the observed file into memory. Callers hold s.mu.
+func (s *Store) loadMarkers(path string) error {
+	return readLines(path, func(line []byte) error {
+		var m Marker
+		if err := json.Unmarshal(line, &m); err != nil {
+			return err
+		}
+		s.markers = append(s.markers, m)
+		return nil
+	})
+}
+
+// RecordMarker appends an observation marker.
+func (s *Store) RecordMarker(event string, at time.Time) error {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := Marker{Event: event, At: at}
+	s.markers = append(s.markers, m)
+	return s.append(observedFile, m)
+}
+
+// Observed returns the periods gvid was observing, oldest first. Markers
+// more than maxGap apart start a new period, and a period without a stop
+// marker, from a crash or the current run, extends maxGap past its last
+// marker. A store with no markers returns nil, meaning coverage is unknown
+// rather than empty.
+func (s *Store) Observed(maxGap time.Duration) []Interval {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return buildIntervals(s.markers, maxGap)
+}
+
+// buildIntervals folds markers sorted oldest first into observed periods.
+func buildIntervals(markers []Marker, maxGap time.Duration) []Interval {
+	if len(markers) == 0 {
+		return nil
+	}
+
+	intervals := []Interval{}
+	open := false
+	// closeOpen ends the open period maxGap after its last marker, but no
+	// later than limit when limit is set
+	closeOpen := func(limit time.Time) {
+		if !open {
+			return
+		}
+		last := &intervals[len(intervals)-1]
+		last.End = last.End.Add(maxGap)
+		if !limit.IsZero() && last.End.After(limit) {
+			last.End = limit
+		}
+		open = false
+	}
+
+	for _, m := range markers {
+		if open && m.At.Sub(intervals[len(intervals)-1].End) > maxGap {
+			closeOpen(time.Time{})
+		}
+		switch {
+		case m.Event == MarkerStop:
+			if open {
+				intervals[len(intervals)-1].End = m.At
+				open = false
+			}
+		case open && m.Event != MarkerStart:
+			intervals[len(intervals)-1].End = m.At
+		default:
+			// A start while open means the previous run stopped uncleanly
+			closeOpen(m.At)
+			intervals = append(intervals, Interval{Start: m.At, End: m.At})
+			open = true
+		}
+	}
+	closeOpen(time.Time{})
+
+	return intervals
+}
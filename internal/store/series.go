@@ -0,0 +1,181 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// seriesFile is the append-only log of metric samples.
+const seriesFile = "series.jsonl"
+
+// Point is one value of a metric series.
+type Point struct {
+	At    time.Time `json:"at"`
+	Value float64   `json:"value"`
+}
+
+// sampleBatch is one line of the series file: every metric sampled at once.
+type sampleBatch struct {
+	At     time.Time          `json:"at"`
+	Values map[string]float64 `json:"values"`
+}
+
+// loadSeries reads the series file into memory. Callers hold s.mu.
+func (s *Store) loadSeries(path string) error {
+	return readLines(path, func(line []byte) error {
+		var batch sampleBatch
+		if err := json.Unmarshal(line, &batch); err != nil {
+			return err
+		}
+		s.addBatch(batch)
+		return nil
+	})
+}
+
+// addBatch adds a batch to the in-memory series. Callers hold s.mu.
+func (s *Store) addBatch(batch sampleBatch) {
+	for name, value := range batch.Values {
+		s.series[name] = append(s.series[name], Point{At: batch.At, Value: value})
+	}
+}
+
+// RecordSamples appends values for several metrics taken at the same time.
+func (s *Store) RecordSamples(at time.Time, values map[string]float64) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := sampleBatch{At: at, Values: values}
+	s.addBatch(batch)
+	return s.append(seriesFile, batch)
+}
+
+// PruneSeries drops points taken before cutoff and whole series that drop
+// reports true for (nil drops none), then rewrites the series file to
+// match. Series left without points are removed.
+func (s *Store) PruneSeries(cutoff time.Time, drop func(name string) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for name, points := range s.series {
+		if drop != nil && drop(name) {
+			delete(s.series, name)
+			changed = true
+			continue
+		}
+
+		kept := points[:0]
+		for _, p := range points {
+			if !p.At.Before(cutoff) {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == len(points) {
+			continue
+		}
+		changed = true
+		if len(kept) == 0 {
+			delete(s.series, name)
+		} else {
+			s.series[name] = kept
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	// Regroup the remaining points into one batch per sample time
+	byTime := make(map[int64]*sampleBatch)
+	for name, points := range s.series {
+		for _, p := range points {
+			batch, ok := byTime[p.At.UnixNano()]
+			if !ok {
+				batch = &sampleBatch{At: p.At, Values: make(map[string]float64)}
+				byTime[p.At.UnixNano()] = batch
+			}
+			batch.Values[name] = p.Value
+		}
+	}
+	batches := make([]*sampleBatch, 0, len(byTime))
+	for _, batch := range byTime {
+		batches = append(batches, batch)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].At.Before(batches[j].At)
+	})
+
+	values := make([]interface{}, len(batches))
+	for i, batch := range batches {
+		values[i] = batch
+	}
+	return s.rewrite(seriesFile, values)
+}
+
+// SeriesNames returns the names of all recorded metrics, sorted.
+func (s *Store) SeriesNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.series))
+	for name := range s.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Series returns the points of a metric between from and to inclusive. A
+// positive step averages points into buckets of that width starting at
+// from, each reported at its bucket start; empty buckets are omitted.
+func (s *Store) Series(name string, from, to time.Time, step time.Duration) []Point {
+	s.mu.RLock()
+	all := s.series[name]
+	var points []Point
+	for _, p := range all {
+		if !p.At.Before(from) && !p.At.After(to) {
+			points = append(points, p)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].At.Before(points[j].At)
+	})
+
+	if step <= 0 {
+		if points == nil {
+			return []Point{}
+		}
+		return points
+	}
+
+	result := []Point{}
+	var sum float64
+	var count int
+	bucket := -1
+	flush := func() {
+		if count > 0 {
+			result = append(result, Point{
+				At:    from.Add(time.Duration(bucket) * step),
+				Value: sum / float64(count),
+			})
+		}
+	}
+	for _, p := range points {
+		b := int(p.At.Sub(from) / step)
+		if b != bucket {
+			flush()
+			bucket, sum, count = b, 0, 0
+		}
+		sum += p.Value
+		count++
+	}
+	flush()
+
+	return result
+}
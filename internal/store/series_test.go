@@ -0,0 +1,106 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeries_PersistsAndBuckets(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	for i, v := range []float64{1, 3, 5, 7} {
+		at := base.Add(time.Duration(i) * time.Minute)
+		if err := s.RecordSamples(at, map[string]float64{"board.blocked": v, "agents.active": 2}); err != nil {
+			t.Fatalf("RecordSamples() returned error: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+
+	names := s.SeriesNames()
+	if len(names) != 2 || names[0] != "agents.active" {
+		t.Errorf("Unexpected names: %v", names)
+	}
+
+	raw := s.Series("board.blocked", base.Add(time.Minute), base.Add(3*time.Minute), 0)
+	if len(raw) != 3 || raw[0].Value != 3 {
+		t.Errorf("Expected 3 raw points in range, got %+v", raw)
+	}
+
+	bucketed := s.Series("board.blocked", base, base.Add(time.Hour), 2*time.Minute)
+	if len(bucketed) != 2 {
+		t.Fatalf("Expected 2 buckets, got %+v", bucketed)
+	}
+	if bucketed[0].Value != 2 || bucketed[1].Value != 6 {
+		t.Errorf("Expected bucket means 2 and 6, got %v and %v", bucketed[0].Value, bucketed[1].Value)
+	}
+	if !bucketed[1].At.Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("Expected bucket at start, got %s", bucketed[1].At)
+	}
+}
+
+func TestSeries_Unknown(t *testing.T) {
+	s, _ := Open("")
+	if points := s.Series("nope", time.Time{}, time.Now(), time.Minute); len(points) != 0 {
+		t.Errorf("Expected no points, got %d", len(points))
+	}
+}
+
+func TestPruneSeries(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		at := base.Add(time.Duration(i) * time.Hour)
+		values := map[string]float64{"board.blocked": float64(i), "convoy.c1.progress": 50}
+		if i == 0 {
+			values["agents.idle"] = 1
+		}
+		if err := s.RecordSamples(at, values); err != nil {
+			t.Fatalf("RecordSamples() returned error: %v", err)
+		}
+	}
+
+	closed := func(name string) bool { return name == "convoy.c1.progress" }
+	if err := s.PruneSeries(base.Add(2*time.Hour), closed); err != nil {
+		t.Fatalf("PruneSeries() returned error: %v", err)
+	}
+
+	// Appends after the rewrite must land in the new file
+	if err := s.RecordSamples(base.Add(4*time.Hour), map[string]float64{"board.blocked": 4}); err != nil {
+		t.Fatalf("RecordSamples() returned error: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	defer s.Close()
+
+	names := s.SeriesNames()
+	if len(names) != 1 || names[0] != "board.blocked" {
+		t.Errorf("Expected only board.blocked to remain, got %v", names)
+	}
+	points := s.Series("board.blocked", time.Time{}, base.Add(time.Hour*24), 0)
+	if len(points) != 3 || points[0].Value != 2 || points[2].Value != 4 {
+		t.Errorf("Expected points 2, 3 and 4, got %+v", points)
+	}
+}
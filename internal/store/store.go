@@ -0,0 +1,190 @@
+// Package store persists what gvid observes over time under its data
+// directory, so history survives daemon restarts.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// historyFile is the append-only log of agent status transitions.
+const historyFile = "agent_history.jsonl"
+
+// Store holds recorded observations in memory and appends them to JSON
+// lines files in its directory.
+type Store struct {
+	dir string // Empty keeps observations in memory only
+
+	mu      sync.RWMutex
+	history map[string][]Transition // By agent address, oldest first
+	series  map[string][]Point      // By metric name, in recording order
+	markers []Marker                // Oldest first
+	files   map[string]*os.File
+}
+
+// Open loads the store in dir, creating the directory if needed. An empty
+// dir returns a store that does not persist anything.
+func Open(dir string) (*Store, error) {
+	s := &Store{
+		dir:     dir,
+		history: make(map[string][]Transition),
+		series:  make(map[string][]Point),
+		files:   make(map[string]*os.File),
+	}
+	if dir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	err := readLines(filepath.Join(dir, historyFile), func(line []byte) error {
+		var t Transition
+		if err := json.Unmarshal(line, &t); err != nil {
+			return err
+		}
+		s.history[t.Address] = append(s.history[t.Address], t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadSeries(filepath.Join(dir, seriesFile)); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadMarkers(filepath.Join(dir, observedFile)); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Dir returns the directory the store persists to, or "" if in memory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Close closes any open files.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for name, f := range s.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.files, name)
+	}
+	return firstErr
+}
+
+// append writes v as one JSON line to the named file. Callers hold s.mu.
+func (s *Store) append(name string, v interface{}) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	f, ok := s.files[name]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(filepath.Join(s.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		s.files[name] = f
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// rewrite replaces the named file with one JSON line per value, through a
+// temporary file so a crash leaves either the old or the new contents.
+// Callers hold s.mu.
+func (s *Store) rewrite(name string, values []interface{}) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to rewrite %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to rewrite %s: %w", name, err)
+	}
+	if err := tmp.Chmod(0640); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to rewrite %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to rewrite %s: %w", name, err)
+	}
+
+	// The append handle points at the old file; reopen on the next write
+	if f, ok := s.files[name]; ok {
+		f.Close()
+		delete(s.files, name)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to rewrite %s: %w", name, err)
+	}
+	return nil
+}
+
+// readLines calls fn for each non-empty line of path. A missing file is
+// not an error, and a truncated final line from an interrupted write is
+// skipped.
+func readLines(path string, fn func(line []byte) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	var pendingErr error
+	for scanner.Scan() {
+		lineNum++
+		// An error on an earlier line is only fatal if more lines follow
+		if pendingErr != nil {
+			return pendingErr
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			pendingErr = fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+	}
+	return scanner.Err()
+}
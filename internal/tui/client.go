@@ -2,12 +2,22 @@
 package tui
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
 )
 
@@ -17,14 +27,167 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// ClientOptions configures how the client connects to the daemon.
+type ClientOptions struct {
+	// Token is sent as a bearer token when the daemon requires one.
+	Token string
+
+	// CAFile is a PEM bundle used to verify the daemon's certificate
+	// instead of the system roots.
+	CAFile string
+
+	// CertFile and KeyFile are a client certificate for daemons that
+	// require mutual TLS.
+	CertFile string
+	KeyFile  string
+}
+
 // NewClient creates a new API client.
-func NewClient(baseURL string) *Client {
+func NewClient(baseURL string, opts ClientOptions) (*Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.CAFile != "" || opts.CertFile != "" || opts.KeyFile != "" {
+		tlsCfg, err := opts.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		base.TLSClientConfig = tlsCfg
+	}
+
+	// unix:///path/to/gvid.sock dials the socket; requests go to a
+	// placeholder host.
+	if path, ok := strings.CutPrefix(baseURL, "unix://"); ok {
+		if path == "" {
+			return nil, fmt.Errorf("unix URL needs a socket path, e.g. unix:///run/gvid/gvid.sock")
+		}
+		base.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+		baseURL = "http://gvid"
+	}
+
+	var transport http.RoundTripper = base
+	if opts.Token != "" {
+		transport = &bearerTransport{token: opts.Token, next: transport}
+	}
+	transport = &revalidatingTransport{next: transport, cache: make(map[string]cachedResponse)}
+
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: transport,
 		},
+	}, nil
+}
+
+// tlsConfig builds the client TLS configuration from the options.
+func (o ClientOptions) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if o.CAFile != "" {
+		data, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
 	}
+
+	return cfg, nil
+}
+
+// bearerTransport adds an Authorization header to every request.
+type bearerTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+// RoundTrip sends req with the bearer token set.
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.next.RoundTrip(req)
+}
+
+// revalidatingTransport remembers GET responses that carry an ETag and
+// sends If-None-Match on the next request for the same URL. A 304 from the
+// daemon is answered from memory, so an unchanged board is not downloaded
+// again on every refresh.
+type revalidatingTransport struct {
+	next  http.RoundTripper
+	mu    sync.Mutex
+	cache map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// RoundTrip sends req, revalidating a cached response if there is one.
+func (t *revalidatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	t.mu.Lock()
+	cached, ok := t.cache[key]
+	t.mu.Unlock()
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		resp.Header = cached.header.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.mu.Lock()
+	t.cache[key] = cachedResponse{etag: etag, header: resp.Header.Clone(), body: body}
+	t.mu.Unlock()
+
+	return resp, nil
 }
 
 // HealthResponse matches the API health response.
@@ -76,6 +239,10 @@ func (c *Client) Board() (*BoardResponse, error) {
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
 	var board BoardResponse
 	if err := json.Unmarshal(body, &board); err != nil {
 		return nil, err
@@ -101,6 +268,10 @@ func (c *Client) Issue(id string) (*model.Issue, error) {
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
 	var issue model.Issue
 	if err := json.Unmarshal(body, &issue); err != nil {
 		return nil, err
@@ -108,3 +279,88 @@ func (c *Client) Issue(id string) (*model.Issue, error) {
 
 	return &issue, nil
 }
+
+// AgentsResponse matches the API agents response.
+type AgentsResponse struct {
+	Agents  []gastown.Agent `json:"agents"`
+	Total   int             `json:"total"`
+	Active  int             `json:"active"`
+	Offline int             `json:"offline"`
+}
+
+// Agents fetches all Gas Town agents.
+func (c *Client) Agents() (*AgentsResponse, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/town/agents")
+	if err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	var agents AgentsResponse
+	if err := json.Unmarshal(body, &agents); err != nil {
+		return nil, err
+	}
+
+	return &agents, nil
+}
+
+// LifecycleResponse matches the API agent start/stop/restart response.
+type LifecycleResponse struct {
+	Address        string `json:"address"`
+	Action         string `json:"action"`
+	Changed        bool   `json:"changed"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+}
+
+// AgentAction starts, stops or restarts an agent's session. Stop and
+// restart are rejected by the daemon unless confirm is set.
+func (c *Client) AgentAction(address, action string, confirm bool) (*LifecycleResponse, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/town/agents/%s/%s", c.baseURL, url.PathEscape(address), action)
+	if confirm {
+		endpoint += "?confirm=true"
+	}
+
+	resp, err := c.httpClient.Post(endpoint, "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	var result LifecycleResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// apiError builds an error from an API error response body.
+func apiError(status int, body []byte) error {
+	var resp struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == "" {
+		return fmt.Errorf("request failed: %s", http.StatusText(status))
+	}
+	return fmt.Errorf("%s (%s)", resp.Error, resp.Code)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/intent-solutions-io/gastown-viewer-intent/internal/gastown"
 	"github.com/intent-solutions-io/gastown-viewer-intent/internal/model"
 )
 
@@ -18,6 +19,7 @@ type View int
 const (
 	ViewBoard View = iota
 	ViewIssue
+	ViewAgents
 )
 
 // Model is the main TUI model.
@@ -35,6 +37,17 @@ type Model struct {
 	issueCur int // selected issue in column
 	width    int
 	height   int
+
+	agents   *AgentsResponse
+	agentCur int            // selected agent
+	pending  *pendingAction // stop/restart awaiting confirmation
+	notice   string         // result of the last agent action
+}
+
+// pendingAction is an agent action waiting for the user to confirm.
+type pendingAction struct {
+	address string
+	action  string
 }
 
 // keyMap defines keybindings.
@@ -48,16 +61,22 @@ type keyMap struct {
 	Refresh key.Binding
 	Quit    key.Binding
 	Help    key.Binding
+	Agents  key.Binding
+	Start   key.Binding
+	Stop    key.Binding
+	Restart key.Binding
+	Confirm key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Left, k.Right, k.Enter, k.Quit, k.Help}
+	return []key.Binding{k.Left, k.Right, k.Enter, k.Agents, k.Quit, k.Help}
 }
 
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Left, k.Right, k.Up, k.Down},
 		{k.Enter, k.Back, k.Refresh, k.Quit},
+		{k.Agents, k.Start, k.Stop, k.Restart},
 	}
 }
 
@@ -71,16 +90,21 @@ var defaultKeys = keyMap{
 	Refresh: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
 	Quit:    key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
 	Help:    key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+	Agents:  key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "agents")),
+	Start:   key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start agent")),
+	Stop:    key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "stop agent")),
+	Restart: key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "restart agent")),
+	Confirm: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "confirm")),
 }
 
-// New creates a new TUI model.
-func New(apiURL string) Model {
+// New creates a new TUI model backed by client.
+func New(client *Client) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	return Model{
-		client:  NewClient(apiURL),
+		client:  client,
 		spinner: s,
 		help:    help.New(),
 		keys:    defaultKeys,
@@ -93,6 +117,9 @@ func New(apiURL string) Model {
 // Messages
 type boardMsg *BoardResponse
 type issueMsg *model.Issue
+type agentsMsg *AgentsResponse
+type lifecycleMsg *LifecycleResponse
+type actionErrMsg error
 type errMsg error
 
 func (m Model) fetchBoard() tea.Msg {
@@ -113,6 +140,32 @@ func (m Model) fetchIssue(id string) tea.Cmd {
 	}
 }
 
+func (m Model) fetchAgents() tea.Msg {
+	agents, err := m.client.Agents()
+	if err != nil {
+		return errMsg(err)
+	}
+	return agentsMsg(agents)
+}
+
+func (m Model) runAgentAction(address, action string, confirm bool) tea.Cmd {
+	return func() tea.Msg {
+		result, err := m.client.AgentAction(address, action, confirm)
+		if err != nil {
+			return actionErrMsg(err)
+		}
+		return lifecycleMsg(result)
+	}
+}
+
+// selectedAgent returns the agent under the cursor in the agents view.
+func (m Model) selectedAgent() (gastown.Agent, bool) {
+	if m.agents == nil || m.agentCur >= len(m.agents.Agents) {
+		return gastown.Agent{}, false
+	}
+	return m.agents.Agents[m.agentCur], true
+}
+
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(m.spinner.Tick, m.fetchBoard)
@@ -128,6 +181,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		// A pending stop/restart captures the next key
+		if m.pending != nil {
+			pending := m.pending
+			m.pending = nil
+			if key.Matches(msg, m.keys.Confirm) {
+				m.loading = true
+				m.notice = ""
+				return m, tea.Batch(m.spinner.Tick, m.runAgentAction(pending.address, pending.action, true))
+			}
+			m.notice = pending.action + " cancelled"
+			return m, nil
+		}
+
+		if m.view == ViewAgents {
+			switch {
+			case key.Matches(msg, m.keys.Start):
+				if agent, ok := m.selectedAgent(); ok {
+					m.loading = true
+					m.notice = ""
+					return m, tea.Batch(m.spinner.Tick, m.runAgentAction(agent.Address(), "start", false))
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keys.Stop):
+				if agent, ok := m.selectedAgent(); ok {
+					m.pending = &pendingAction{address: agent.Address(), action: "stop"}
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keys.Restart):
+				if agent, ok := m.selectedAgent(); ok {
+					m.pending = &pendingAction{address: agent.Address(), action: "restart"}
+				}
+				return m, nil
+			}
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
@@ -139,10 +229,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Refresh):
 			m.loading = true
 			m.err = nil
+			if m.view == ViewAgents {
+				return m, tea.Batch(m.spinner.Tick, m.fetchAgents)
+			}
 			return m, tea.Batch(m.spinner.Tick, m.fetchBoard)
 
+		case key.Matches(msg, m.keys.Agents):
+			if m.view != ViewAgents {
+				m.view = ViewAgents
+				m.notice = ""
+				m.loading = true
+				return m, tea.Batch(m.spinner.Tick, m.fetchAgents)
+			}
+			return m, nil
+
 		case key.Matches(msg, m.keys.Back):
-			if m.view == ViewIssue {
+			if m.view == ViewIssue || m.view == ViewAgents {
 				m.view = ViewBoard
 				m.issue = nil
 			}
@@ -166,6 +268,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.view == ViewBoard && m.issueCur > 0 {
 				m.issueCur--
 			}
+			if m.view == ViewAgents && m.agentCur > 0 {
+				m.agentCur--
+			}
 			return m, nil
 
 		case key.Matches(msg, m.keys.Down):
@@ -175,6 +280,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.issueCur++
 				}
 			}
+			if m.view == ViewAgents && m.agents != nil && m.agentCur < len(m.agents.Agents)-1 {
+				m.agentCur++
+			}
 			return m, nil
 
 		case key.Matches(msg, m.keys.Enter):
@@ -209,6 +317,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = nil
 		return m, nil
 
+	case agentsMsg:
+		m.loading = false
+		m.agents = msg
+		if m.agentCur >= len(msg.Agents) {
+			m.agentCur = 0
+		}
+		m.err = nil
+		return m, nil
+
+	case lifecycleMsg:
+		m.loading = false
+		if msg.Changed {
+			m.notice = fmt.Sprintf("%s %s: %s -> %s", msg.Action, msg.Address, msg.PreviousStatus, msg.Status)
+		} else {
+			m.notice = fmt.Sprintf("%s %s: already %s", msg.Action, msg.Address, msg.Status)
+		}
+		return m, m.fetchAgents
+
+	case actionErrMsg:
+		m.loading = false
+		m.notice = "Error: " + msg.Error()
+		return m, nil
+
 	case errMsg:
 		m.loading = false
 		m.err = msg
@@ -285,6 +416,8 @@ func (m Model) View() string {
 		content = m.viewBoard()
 	case ViewIssue:
 		content = m.viewIssue()
+	case ViewAgents:
+		content = m.viewAgents()
 	}
 
 	helpView := m.help.View(m.keys)
@@ -435,3 +568,52 @@ func (m Model) viewIssue() string {
 
 	return detailStyle.Width(m.width - 4).Render(b.String())
 }
+
+func (m Model) viewAgents() string {
+	if m.agents == nil {
+		return m.viewLoading()
+	}
+
+	var b strings.Builder
+
+	title := titleStyle.Render(fmt.Sprintf("Agents (%d active, %d offline)", m.agents.Active, m.agents.Offline))
+	if m.loading {
+		title += " " + m.spinner.View()
+	}
+	b.WriteString(title + "\n\n")
+
+	for i, agent := range m.agents.Agents {
+		var statusStyle lipgloss.Style
+		switch agent.Status {
+		case gastown.StatusActive:
+			statusStyle = statusDone
+		case gastown.StatusStuck:
+			statusStyle = statusBlocked
+		case gastown.StatusIdle:
+			statusStyle = statusInProgress
+		default:
+			statusStyle = statusPending
+		}
+
+		style := issueStyle
+		prefix := "  "
+		if i == m.agentCur {
+			style = selectedIssueStyle
+			prefix = "> "
+		}
+
+		b.WriteString(fmt.Sprintf("%s%s %s\n",
+			prefix,
+			style.Render(fmt.Sprintf("%-28s", agent.Address())),
+			statusStyle.Render(string(agent.Status))))
+	}
+
+	b.WriteString("\n")
+	if m.pending != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("%s %s? (y/n)", m.pending.action, m.pending.address)))
+	} else if m.notice != "" {
+		b.WriteString(labelStyle.Render(m.notice))
+	}
+
+	return b.String()
+}
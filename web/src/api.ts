@@ -2,6 +2,24 @@
 
 const API_BASE = '/api/v1';
 
+const TOKEN_KEY = 'gvid_token';
+
+// apiToken returns the bearer token for a daemon started with --token-file.
+// Opening the UI with ?token=... stores it for later visits.
+function apiToken(): string | null {
+  const fromURL = new URLSearchParams(window.location.search).get('token');
+  if (fromURL) {
+    localStorage.setItem(TOKEN_KEY, fromURL);
+    return fromURL;
+  }
+  return localStorage.getItem(TOKEN_KEY);
+}
+
+function apiFetch(path: string): Promise<Response> {
+  const token = apiToken();
+  return fetch(path, token ? { headers: { Authorization: `Bearer ${token}` } } : undefined);
+}
+
 export type Status = 'pending' | 'in_progress' | 'done' | 'blocked';
 export type Priority = 'critical' | 'high' | 'medium' | 'low';
 
@@ -90,25 +108,25 @@ export interface GraphResponse {
 }
 
 export async function fetchHealth(): Promise<HealthResponse> {
-  const res = await fetch(`${API_BASE}/health`);
+  const res = await apiFetch(`${API_BASE}/health`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchBoard(): Promise<BoardResponse> {
-  const res = await fetch(`${API_BASE}/board`);
+  const res = await apiFetch(`${API_BASE}/board`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchIssue(id: string): Promise<Issue> {
-  const res = await fetch(`${API_BASE}/issues/${encodeURIComponent(id)}`);
+  const res = await apiFetch(`${API_BASE}/issues/${encodeURIComponent(id)}`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchGraph(format: 'json' | 'dot' = 'json'): Promise<GraphResponse | string> {
-  const res = await fetch(`${API_BASE}/graph?format=${format}`);
+  const res = await apiFetch(`${API_BASE}/graph?format=${format}`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   if (format === 'dot') {
     return res.text();
@@ -117,13 +135,13 @@ export async function fetchGraph(format: 'json' | 'dot' = 'json'): Promise<Graph
 }
 
 export async function fetchGraphJSON(): Promise<GraphResponse> {
-  const res = await fetch(`${API_BASE}/graph?format=json`);
+  const res = await apiFetch(`${API_BASE}/graph?format=json`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchGraphDOT(): Promise<string> {
-  const res = await fetch(`${API_BASE}/graph?format=dot`);
+  const res = await apiFetch(`${API_BASE}/graph?format=dot`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.text();
 }
@@ -219,37 +237,37 @@ export interface ConvoysResponse {
 // Gas Town API calls
 
 export async function fetchTownStatus(): Promise<TownStatus> {
-  const res = await fetch(`${API_BASE}/town/status`);
+  const res = await apiFetch(`${API_BASE}/town/status`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchTown(): Promise<Town> {
-  const res = await fetch(`${API_BASE}/town`);
+  const res = await apiFetch(`${API_BASE}/town`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchRigs(): Promise<RigsResponse> {
-  const res = await fetch(`${API_BASE}/town/rigs`);
+  const res = await apiFetch(`${API_BASE}/town/rigs`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchAgents(): Promise<AgentsResponse> {
-  const res = await fetch(`${API_BASE}/town/agents`);
+  const res = await apiFetch(`${API_BASE}/town/agents`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchConvoys(): Promise<ConvoysResponse> {
-  const res = await fetch(`${API_BASE}/town/convoys`);
+  const res = await apiFetch(`${API_BASE}/town/convoys`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchConvoy(id: string): Promise<Convoy> {
-  const res = await fetch(`${API_BASE}/town/convoys/${encodeURIComponent(id)}`);
+  const res = await apiFetch(`${API_BASE}/town/convoys/${encodeURIComponent(id)}`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
@@ -295,13 +313,13 @@ export interface MoleculesResponse {
 // Molecule API calls
 
 export async function fetchMolecules(): Promise<MoleculesResponse> {
-  const res = await fetch(`${API_BASE}/town/molecules`);
+  const res = await apiFetch(`${API_BASE}/town/molecules`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }
 
 export async function fetchMolecule(id: string): Promise<Molecule> {
-  const res = await fetch(`${API_BASE}/town/molecules/${encodeURIComponent(id)}`);
+  const res = await apiFetch(`${API_BASE}/town/molecules/${encodeURIComponent(id)}`);
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
   return res.json();
 }